@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+var uuidRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// conceptURIPaths lists, for each FT host, the path families concept URIs are published under
+var conceptURIPaths = map[string]map[string]bool{
+	"api.ft.com": {"things": true, "concepts": true, "organisations": true, "people": true, "brands": true},
+	"www.ft.com": {"thing": true, "things": true},
+}
+
+// parseConceptID returns the lower case uuid of a concept given either as a bare uuid or as an FT concept URI,
+// such as http://api.ft.com/things/{uuid}, https://api.ft.com/organisations/{uuid} or http://www.ft.com/thing/{uuid}
+func parseConceptID(value string) (string, error) {
+	id := value
+	if strings.Contains(value, "://") {
+		u, err := url.Parse(value)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.User != nil || u.RawQuery != "" || u.Fragment != "" {
+			return "", unsupportedConceptURIError(value)
+		}
+		family, uuid := path.Split(strings.TrimPrefix(u.Path, "/"))
+		if !conceptURIPaths[strings.ToLower(u.Host)][strings.TrimSuffix(family, "/")] {
+			return "", unsupportedConceptURIError(value)
+		}
+		id = uuid
+	}
+
+	if !uuidRegex.MatchString(id) {
+		return "", fmt.Errorf("%s extracted from request URL was not valid uuid", id)
+	}
+	return strings.ToLower(id), nil
+}
+
+func unsupportedConceptURIError(value string) error {
+	return fmt.Errorf("%s is not a supported concept URI. Expecting a uuid, an http(s)://api.ft.com/things/, concepts/, organisations/, people/ or brands/ URI, or an http(s)://www.ft.com/thing/ URI", value)
+}
@@ -0,0 +1,38 @@
+package content
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor marks the last content item of a page, the next page starts right after it.
+// Content is ordered by its sort key, the tie key of sorts that have one, and the uuid as
+// a last tie-break, so the position stays the same when new content is published between requests.
+type Cursor struct {
+	Sort string  `json:"s"`
+	Key  float64 `json:"k"`
+	Tie  float64 `json:"t,omitempty"`
+	UUID string  `json:"u"`
+}
+
+// Encode returns the opaque representation of the cursor handed out to clients
+func (c Cursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor parses a cursor previously returned by Encode
+func DecodeCursor(value string) (Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(b, &c); err != nil || c.UUID == "" || !IsValidSort(c.Sort) {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
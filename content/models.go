@@ -1,10 +1,73 @@
 package content
 
 const (
-	ThingsPrefix = "http://www.ft.com/things/"
+	ThingsPrefix          = "http://www.ft.com/things/"
+	ContentOntologyPrefix = "http://www.ft.com/ontology/content/"
 )
 
 type Content struct {
 	ID     string `json:"id"`
 	APIURL string `json:"apiUrl"`
+	// Optional fields, only returned when they are selected in the request
+	Title         string   `json:"title,omitempty"`
+	PublishedDate string   `json:"publishedDate,omitempty"`
+	Types         []string `json:"types,omitempty"`
+	// Annotations explain how the content matched the concept, only returned when requested
+	Annotations []Annotation `json:"annotations,omitempty"`
+	// ExpandedFrom lists the narrower, subsidiary or membership concepts the content was found through,
+	// it is only set when the content isn't annotated with the queried concepts themselves
+	ExpandedFrom []string `json:"expandedFrom,omitempty"`
+}
+
+// Annotation links a content item to one of the concepts concorded with the requested concept
+type Annotation struct {
+	Predicate       string   `json:"predicate"`
+	ConceptID       string   `json:"conceptId"`
+	Authority       string   `json:"authority,omitempty"`
+	RelevanceScore  *float64 `json:"relevanceScore,omitempty"`
+	ConfidenceScore *float64 `json:"confidenceScore,omitempty"`
+}
+
+// ContentPage is a page of the content annotated with a concept
+type ContentPage struct {
+	Content []Content
+	// NextCursor points at the last item of the page, it is nil on the last page
+	NextCursor *Cursor
+	// Total is the number of content items across all pages, only set when requested
+	Total int
+	// Concepts are the queried concepts that were found
+	Concepts []ResolvedConcept
+}
+
+// ResolvedConcept is a queried concept along with the canonical concept it is concorded with
+type ResolvedConcept struct {
+	UUID string `json:"uuid"`
+	// CanonicalUUID is empty when the concept isn't concorded, only content annotated directly with it is returned then
+	CanonicalUUID string `json:"canonicalUUID"`
+}
+
+// ConceptCandidate is a canonical concept matching a label lookup
+type ConceptCandidate struct {
+	ID        string   `json:"id"`
+	APIURL    string   `json:"apiUrl"`
+	PrefLabel string   `json:"prefLabel"`
+	Types     []string `json:"types"`
+}
+
+// Canonical returns the uuid of the canonical concept, which is the concept itself when it isn't concorded
+func (c ResolvedConcept) Canonical() string {
+	if c.CanonicalUUID == "" {
+		return c.UUID
+	}
+	return c.CanonicalUUID
+}
+
+// Concorded reports whether all the concepts were resolved through concordance
+func (p ContentPage) Concorded() bool {
+	for _, c := range p.Concepts {
+		if c.CanonicalUUID == "" {
+			return false
+		}
+	}
+	return true
 }
@@ -3,6 +3,9 @@ package content
 import (
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/Financial-Times/neo-model-utils-go/mapper"
 	"github.com/Financial-Times/neo-utils-go/neoutils"
@@ -11,6 +14,122 @@ import (
 
 var ErrContentNotFound = errors.New("content not found")
 
+// ErrConceptNotFound is returned instead of ErrContentNotFound when no content was found because
+// one of the queried concepts doesn't exist
+var ErrConceptNotFound = errors.New("concept not found")
+
+// predicateRelationships maps the annotation predicates exposed by the public API
+// to the relationship types the annotations are stored as in Neo4j
+var predicateRelationships = map[string]string{
+	"mentions":                "MENTIONS",
+	"isClassifiedBy":          "IS_CLASSIFIED_BY",
+	"implicitlyClassifiedBy":  "IMPLICITLY_CLASSIFIED_BY",
+	"about":                   "ABOUT",
+	"isPrimarilyClassifiedBy": "IS_PRIMARILY_CLASSIFIED_BY",
+	"majorMentions":           "MAJOR_MENTIONS",
+	"hasAuthor":               "HAS_AUTHOR",
+	"hasContributor":          "HAS_CONTRIBUTOR",
+	"hasDisplayTag":           "HAS_DISPLAY_TAG",
+	"hasBrand":                "HAS_BRAND",
+}
+
+// relationshipPredicates maps the annotation relationship types back to their predicates
+var relationshipPredicates = map[string]string{}
+
+func init() {
+	for predicate, relType := range predicateRelationships {
+		relationshipPredicates[relType] = predicate
+	}
+}
+
+// contentTypes holds the node labels that identify the type of a content item
+var contentTypes = map[string]bool{
+	"Article":         true,
+	"Video":           true,
+	"Audio":           true,
+	"LiveBlogPackage": true,
+	"LiveBlogPost":    true,
+	"ContentPackage":  true,
+	"ImageSet":        true,
+	"Graphic":         true,
+	"DynamicContent":  true,
+	"Clip":            true,
+	"ClipSet":         true,
+}
+
+// fieldProjections maps the optional content fields to the properties returned for them
+var fieldProjections = map[string]string{
+	"title":         "c.title as title",
+	"publishedDate": "c.publishedDate as publishedDate",
+	// types are built from the labels which are always returned
+	"types": "",
+}
+
+// DefaultSort orders content by publish date, newest first
+const DefaultSort = "publishedDate"
+
+// DefaultRelevanceHalfLife is used by the recency-relevance sort when no half-life is requested
+const DefaultRelevanceHalfLife = 7 * 24 * time.Hour
+
+type sortOrder struct {
+	// key is the expression content is sorted by, it can aggregate the annotation relationships
+	key string
+	// tieKey orders content with the same sort key, it is left out when the key is unique enough
+	tieKey     string
+	descending bool
+}
+
+// sortOrders holds the supported orderings of the content, missing values are sorted as the oldest
+var sortOrders = map[string]sortOrder{
+	"publishedDate":  {key: "coalesce(c.publishedDateEpoch, 0)", descending: true},
+	"-publishedDate": {key: "coalesce(c.publishedDateEpoch, 0)", descending: false},
+	// annotations are often made at the same time and share the same scores,
+	// such content is returned most recently published first
+	"annotatedDate": {key: "coalesce(max(rel.annotatedDateEpoch), 0)", tieKey: "coalesce(c.publishedDateEpoch, 0)", descending: true},
+	"relevance":     {key: "coalesce(max(rel.relevanceScore), 0)", tieKey: "coalesce(c.publishedDateEpoch, 0)", descending: true},
+	// Halving the relevance score costs as much as being one half-life older, which ranks content by
+	// relevance * 2^(-age/halfLife) while keeping the key independent of the time of the request.
+	// Missing or tiny scores are floored so that the logarithm stays finite.
+	"recency-relevance": {
+		key: `coalesce(c.publishedDateEpoch, 0) + {halfLifeSeconds} * log(CASE WHEN max(rel.relevanceScore) > 0.01
+				THEN max(rel.relevanceScore) ELSE 0.01 END) / log(2)`,
+		descending: true,
+	},
+}
+
+// IsValidPredicate reports whether the given annotation predicate is supported
+func IsValidPredicate(predicate string) bool {
+	_, ok := predicateRelationships[predicate]
+	return ok
+}
+
+// IsValidSort reports whether content can be sorted in the given order
+func IsValidSort(name string) bool {
+	_, ok := sortOrders[name]
+	return ok
+}
+
+// IsValidField reports whether the given optional content field can be selected
+func IsValidField(field string) bool {
+	_, ok := fieldProjections[field]
+	return ok
+}
+
+// IsValidConceptType reports whether the given type is a concept type that concepts can be looked up by
+func IsValidConceptType(conceptType string) bool {
+	for t := mapper.ParentType(conceptType); t != ""; t = mapper.ParentType(t) {
+		if t == "Concept" {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidContentType reports whether the given content type can be used to filter content
+func IsValidContentType(contentType string) bool {
+	return contentTypes[contentType]
+}
+
 // CypherDriver struct
 type ConceptService struct {
 	conn neoutils.NeoConnection
@@ -21,6 +140,41 @@ type RequestParams struct {
 	ContentLimit  int
 	FromDateEpoch int64
 	ToDateEpoch   int64
+	// InclusiveDateBounds includes content published exactly at FromDateEpoch or ToDateEpoch
+	InclusiveDateBounds bool
+	// MinRelevance and MinConfidence only match annotations scored at least as high, zero means no threshold
+	MinRelevance  float64
+	MinConfidence float64
+	Predicates    []string
+	// ContentTypes restricts the results to content with at least one of the given labels
+	ContentTypes []string
+	// ExcludedConceptUUIDs removes the content annotated with any of the given concepts, through their concordance
+	ExcludedConceptUUIDs []string
+	// NarrowerDepth includes the content of the concepts up to that many levels below the queried ones,
+	// through HAS_PARENT and HAS_BROADER relationships. Zero only returns the content of the queried concepts.
+	NarrowerDepth int
+	// IncludeSubsidiaries includes the content of the subsidiaries of the queried organisations
+	IncludeSubsidiaries bool
+	// IncludeMemberships includes the content of the organisations the queried people hold memberships in
+	IncludeMemberships bool
+	// MatchAnyConcept returns the content annotated with any of the concepts instead of all of them
+	MatchAnyConcept bool
+	// ExcludedContentTypes removes content with any of the given labels from the results
+	ExcludedContentTypes []string
+	// Sort is the order of the content, defaults to DefaultSort
+	Sort string
+	// RelevanceHalfLife is how fast the relevance decays with age for the recency-relevance sort,
+	// defaults to DefaultRelevanceHalfLife
+	RelevanceHalfLife time.Duration
+	// Cursor continues the results after the given position, Page is ignored when it is set.
+	// It must have been returned for the same Sort.
+	Cursor *Cursor
+	// IncludeTotal counts all the content matching the request, which needs an extra query
+	IncludeTotal bool
+	// Fields selects the optional content fields to return
+	Fields []string
+	// IncludeAnnotations returns the annotations through which each content item matched
+	IncludeAnnotations bool
 }
 
 func NewContentByConceptService(neoURL string, neoConf neoutils.ConnectionConfig) (*ConceptService, error) {
@@ -39,58 +193,473 @@ func (cd *ConceptService) CheckConnection() (string, error) {
 	return "Database connection is OK", nil
 }
 
-func (cd *ConceptService) GetContentForConcept(conceptUUID string, params RequestParams) ([]Content, error) {
+// ResolveConcepts returns the given concepts that exist, along with the canonical concept they are concorded with
+func (cd *ConceptService) ResolveConcepts(conceptUUIDs []string) ([]ResolvedConcept, error) {
+	var resolved []ResolvedConcept
+	err := cd.conn.CypherBatch([]*neoism.CypherQuery{resolveQuery(conceptUUIDs, &resolved)})
+	if err != nil {
+		return nil, err
+	}
+	return resolved, nil
+}
+
+// LEIAuthority identifies organisations by their Legal Entity Identifier, which is stored as leiCode rather than authorityValue
+const LEIAuthority = "LEI"
+
+// FindConceptsByIdentifier returns the uuids of the canonical concepts with a source concept
+// holding the given authority identifier. LEI codes are only stored on the canonical concepts.
+func (cd *ConceptService) FindConceptsByIdentifier(authority, identifierValue string) ([]string, error) {
+	statement := `
+			MATCH (source:Concept{authority:{authority}, authorityValue:{identifierValue}})-[:EQUIVALENT_TO]->(canon:Concept)
+			RETURN DISTINCT canon.prefUUID as uuid ORDER BY uuid`
+	if authority == LEIAuthority {
+		statement = `
+			MATCH (canon:Concept{leiCode:{identifierValue}})
+			WHERE exists(canon.prefUUID)
+			RETURN DISTINCT canon.prefUUID as uuid ORDER BY uuid`
+	}
+
+	var results []struct {
+		UUID string `json:"uuid"`
+	}
+	query := &neoism.CypherQuery{
+		Statement:  statement,
+		Parameters: neoism.Props{"authority": authority, "identifierValue": identifierValue},
+		Result:     &results,
+	}
+	if err := cd.conn.CypherBatch([]*neoism.CypherQuery{query}); err != nil {
+		return nil, err
+	}
+
+	var conceptUUIDs []string
+	for _, result := range results {
+		conceptUUIDs = append(conceptUUIDs, result.UUID)
+	}
+	return conceptUUIDs, nil
+}
+
+// maxLabelCandidates caps the number of concepts a label lookup returns
+const maxLabelCandidates = 20
+
+// FindConceptsByLabel returns the canonical concepts whose prefLabel is exactly the given label. When conceptType
+// is given only concepts of that type are returned, and their aliases are matched too. concepts-rw-neo4j only
+// stores the aliases of the source concepts on the canonical concept. At most maxLabelCandidates are returned.
+func (cd *ConceptService) FindConceptsByLabel(label, conceptType string) ([]ConceptCandidate, error) {
+	// source concepts have a prefLabel too but no prefUUID. The schema is owned by concepts-rw-neo4j,
+	// the prefLabel lookup is only index-backed once it creates an index on Concept(prefLabel).
+	statement := `
+			MATCH (canon:Concept{prefLabel:{label}})
+			WHERE exists(canon.prefUUID)`
+	if conceptType != "" {
+		if !IsValidConceptType(conceptType) {
+			return nil, fmt.Errorf("unsupported concept type %s", conceptType)
+		}
+		// aliases are lists, which can't be indexed. Every concept of the type is scanned for them,
+		// so aliases are only matched when the type narrows the scan down.
+		statement += ` AND canon:` + conceptType + `
+			RETURN canon.prefUUID as uuid, canon.prefLabel as prefLabel, labels(canon) as types
+			LIMIT {maxCandidates}
+			UNION
+			MATCH (canon:` + conceptType + `)
+			WHERE {label} IN canon.aliases AND exists(canon.prefUUID)`
+	}
+	statement += `
+			RETURN canon.prefUUID as uuid, canon.prefLabel as prefLabel, labels(canon) as types
+			LIMIT {maxCandidates}`
+
+	var results []struct {
+		UUID      string   `json:"uuid"`
+		PrefLabel string   `json:"prefLabel"`
+		Types     []string `json:"types"`
+	}
+	query := &neoism.CypherQuery{
+		Statement:  statement,
+		Parameters: neoism.Props{"label": label, "maxCandidates": maxLabelCandidates},
+		Result:     &results,
+	}
+	if err := cd.conn.CypherBatch([]*neoism.CypherQuery{query}); err != nil {
+		return nil, err
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].PrefLabel != results[j].PrefLabel {
+			return results[i].PrefLabel < results[j].PrefLabel
+		}
+		return results[i].UUID < results[j].UUID
+	})
+	if len(results) > maxLabelCandidates {
+		results = results[:maxLabelCandidates]
+	}
+
+	var candidates []ConceptCandidate
+	for _, result := range results {
+		candidates = append(candidates, ConceptCandidate{
+			ID:        mapper.IDURL(result.UUID),
+			APIURL:    mapper.APIURL(result.UUID, result.Types, ""),
+			PrefLabel: result.PrefLabel,
+			Types:     mapper.TypeURIs(result.Types),
+		})
+	}
+	return candidates, nil
+}
+
+// GetContentForConcepts returns the content annotated with every one of the given concepts, or with any
+// of them when MatchAnyConcept is set. Each concept is resolved through its own concordance.
+// When nothing matches, ErrConceptNotFound is returned if one of the concepts doesn't exist, or none of
+// them when MatchAnyConcept is set, and ErrContentNotFound otherwise, along with the concepts that were resolved.
+func (cd *ConceptService) GetContentForConcepts(conceptUUIDs []string, params RequestParams) (ContentPage, error) {
 	var results []struct {
-		UUID  string   `json:"uuid"`
-		Types []string `json:"types"`
+		UUID          string   `json:"uuid"`
+		Types         []string `json:"types"`
+		SortKey       float64  `json:"sortKey"`
+		TieKey        float64  `json:"tieKey"`
+		Title         string   `json:"title"`
+		PublishedDate string   `json:"publishedDate"`
+		Annotations   []struct {
+			RelType         string   `json:"relType"`
+			LeafUUID        string   `json:"leafUUID"`
+			Authority       string   `json:"authority"`
+			RelevanceScore  *float64 `json:"relevanceScore"`
+			ConfidenceScore *float64 `json:"confidenceScore"`
+		} `json:"annotations"`
+		Expanded     bool     `json:"expanded"`
+		ExpandedFrom []string `json:"expandedFrom"`
 	}
 	var query *neoism.CypherQuery
 
-	var whereClause string
-	if params.FromDateEpoch > 0 && params.ToDateEpoch > 0 {
-		whereClause = " WHERE c.publishedDateEpoch > {fromDate} AND c.publishedDateEpoch < {toDate}"
+	fromOperator, toOperator := ">", "<"
+	if params.InclusiveDateBounds {
+		fromOperator, toOperator = ">=", "<="
+	}
+
+	var conditions []string
+	if params.FromDateEpoch > 0 {
+		conditions = append(conditions, "c.publishedDateEpoch "+fromOperator+" {fromDate}")
+	}
+	if params.ToDateEpoch > 0 {
+		conditions = append(conditions, "c.publishedDateEpoch "+toOperator+" {toDate}")
+	}
+	if params.MinRelevance > 0 {
+		conditions = append(conditions, "rel.relevanceScore >= {minRelevance}")
+	}
+	if params.MinConfidence > 0 {
+		conditions = append(conditions, "rel.confidenceScore >= {minConfidence}")
+	}
+	if len(params.ContentTypes) > 0 {
+		conditions = append(conditions, "ANY(label IN labels(c) WHERE label IN {contentTypes})")
+	}
+	if len(params.ExcludedContentTypes) > 0 {
+		conditions = append(conditions, "NONE(label IN labels(c) WHERE label IN {excludedContentTypes})")
 	}
 
 	// skipCount determines how many rows to skip before returning the results
 	skipCount := (params.Page - 1) * params.ContentLimit
 
+	sortName := params.Sort
+	if sortName == "" {
+		sortName = DefaultSort
+	}
+	order := sortOrders[sortName]
+	direction, cursorOperator := "ASC", ">"
+	if order.descending {
+		direction, cursorOperator = "DESC", "<"
+	}
+
+	halfLife := params.RelevanceHalfLife
+	if halfLife <= 0 {
+		halfLife = DefaultRelevanceHalfLife
+	}
+
+	// content is ordered by the sort key, then the tie key if there is one, then the uuid
+	orderBy, tieCondition := ` ORDER BY sortKey `+direction+`, c.uuid `+direction, "c.uuid "+cursorOperator+" {cursorUUID}"
+	if order.tieKey != "" {
+		orderBy = ` ORDER BY sortKey ` + direction + `, tieKey ` + direction + `, c.uuid ` + direction
+		tieCondition = "(tieKey " + cursorOperator + " {cursorTie} OR (tieKey = {cursorTie} AND " + tieCondition + "))"
+	}
+
+	var cursorConditions []string
+	var cursorKey, cursorTie float64
+	var cursorUUID string
+	if params.Cursor != nil {
+		cursorConditions = append(cursorConditions, "(sortKey "+cursorOperator+" {cursorKey} OR (sortKey = {cursorKey} AND "+tieCondition+"))")
+		cursorKey, cursorTie, cursorUUID = params.Cursor.Key, params.Cursor.Tie, params.Cursor.UUID
+		skipCount = 0
+	}
+
 	parameters := neoism.Props{
-		"conceptUUID":     conceptUUID,
-		"skipCount":       skipCount,
-		"maxContentItems": params.ContentLimit,
-		"fromDate":        params.FromDateEpoch,
-		"toDate":          params.ToDateEpoch}
+		"conceptUUIDs":         conceptUUIDs,
+		"excludedConceptUUIDs": params.ExcludedConceptUUIDs,
+		"skipCount":            skipCount,
+		// one more item than requested is fetched to find out whether there is a next page
+		"maxContentItems":      params.ContentLimit + 1,
+		"cursorKey":            cursorKey,
+		"cursorTie":            cursorTie,
+		"cursorUUID":           cursorUUID,
+		"halfLifeSeconds":      halfLife.Seconds(),
+		"fromDate":             params.FromDateEpoch,
+		"toDate":               params.ToDateEpoch,
+		"minRelevance":         params.MinRelevance,
+		"minConfidence":        params.MinConfidence,
+		"contentTypes":         params.ContentTypes,
+		"excludedContentTypes": params.ExcludedContentTypes}
+
+	// when content has to match all the concepts, nothing matches unless every queried concept is found
+	// and the content is annotated with each of the canonical concepts
+	var foundConditions, conceptConditions []string
+	if !params.MatchAnyConcept {
+		foundConditions = append(foundConditions, "found = size({conceptUUIDs})")
+		conceptConditions = append(conceptConditions, "matchedConcepts = size(canons)")
+	}
 
-	// New concordance model
+	// excluded concepts are resolved to their canonical concepts up front,
+	// content annotated with any of their source concepts is then removed
+	excludeStatement, excludedColumn := ``, ``
+	if len(params.ExcludedConceptUUIDs) > 0 {
+		excludeStatement = `
+			OPTIONAL MATCH (excludedQueried:Thing)
+			WHERE excludedQueried.uuid IN {excludedConceptUUIDs} AND NOT excludedQueried:Content
+			OPTIONAL MATCH (excludedQueried)-[:EQUIVALENT_TO]->(excludedCanonical:Concept)
+			WITH collect(DISTINCT coalesce(excludedCanonical, excludedQueried)) as excludedCanons`
+		excludedColumn = `excludedCanons, `
+		conditions = append(conditions, "NONE(excluded IN excludedCanons WHERE (c)-->()-[:EQUIVALENT_TO*0..1]->(excluded))")
+	}
+
+	annotatedConcept, expansion := "canon", ``
+	patterns := expansionPatterns(params)
+	if len(patterns) > 0 {
+		annotatedConcept, expansion = "concept", expansionStatement(patterns, excludedColumn)
+	}
+
+	// New concordance model, a concept that isn't concorded stands for itself
+	// and only the content annotated directly with it is returned
+	matchStatement := excludeStatement + resolveStatement + `
+			WITH ` + excludedColumn + `collect(DISTINCT coalesce(canonical, queried)) as canons, count(DISTINCT queried) as found` +
+		whereClause(foundConditions) + `
+			UNWIND canons as canon` +
+		expansion + `
+			MATCH (` + annotatedConcept + `)<-[:EQUIVALENT_TO*0..1]-(leaves)<-[rel` + relationshipFilter(params.Predicates) + `]-(c:Content)`
+
+	conceptsClause := ` WITH c, canons, count(DISTINCT canon) as matchedConcepts`
+
+	withClause := conceptsClause + `, ` + order.key + ` as sortKey`
+	returnClause := ``
+	if order.tieKey != "" {
+		withClause += `, ` + order.tieKey + ` as tieKey`
+		returnClause = `, tieKey`
+	}
+	if len(patterns) > 0 {
+		// content is marked as expanded when none of its annotations is with the queried concepts themselves
+		withClause += `, min(CASE WHEN concept = canon THEN 0 ELSE 1 END) = 1 as expanded,
+				collect(DISTINCT CASE WHEN concept <> canon THEN concept.prefUUID END) as expandedFrom`
+		returnClause += `, expanded, expandedFrom`
+	}
+	if params.IncludeAnnotations {
+		withClause += `, collect(DISTINCT {relType: type(rel), leafUUID: leaves.uuid, authority: leaves.authority,
+				relevanceScore: rel.relevanceScore, confidenceScore: rel.confidenceScore}) as annotations`
+		returnClause += `, annotations`
+	}
+
+	// the cursor is applied once the sort key is known, and only restricts the current page.
+	// A WITH can't be filtered before it is sorted, so the content is sorted by a WITH of its own.
 	query = &neoism.CypherQuery{
-		Statement: `
-			MATCH (:Concept{uuid:{conceptUUID}})-[:EQUIVALENT_TO]->(canon:Concept)
-			MATCH (canon)<-[:EQUIVALENT_TO]-(leaves)<-[]-(c:Content)` +
-			whereClause +
-			` WITH DISTINCT c
-			ORDER BY c.publishedDateEpoch DESC
+		Statement: matchStatement +
+			whereClause(conditions) +
+			withClause +
+			whereClause(append(conceptConditions, cursorConditions...)) +
+			` WITH *` + orderBy + `
 			SKIP ({skipCount})
-			RETURN c.uuid as uuid, labels(c) as types
-			LIMIT({maxContentItems})`,
+			RETURN c.uuid as uuid, labels(c) as types, sortKey` +
+			fieldsProjection(params.Fields) + returnClause +
+			` LIMIT({maxContentItems})`,
 		Parameters: parameters,
 		Result:     &results,
 	}
-	err := cd.conn.CypherBatch([]*neoism.CypherQuery{query})
+	queries := []*neoism.CypherQuery{query}
+
+	var resolved []ResolvedConcept
+	queries = append(queries, resolveQuery(conceptUUIDs, &resolved))
+
+	var totalResults []struct {
+		Total int `json:"total"`
+	}
+	if params.IncludeTotal {
+		queries = append(queries, &neoism.CypherQuery{
+			Statement: matchStatement +
+				whereClause(conditions) +
+				conceptsClause +
+				whereClause(conceptConditions) +
+				` RETURN count(c) as total`,
+			Parameters: parameters,
+			Result:     &totalResults,
+		})
+	}
+
+	err := cd.conn.CypherBatch(queries)
 	if err != nil {
-		return nil, err
+		return ContentPage{}, err
 	}
 
 	if len(results) == 0 {
-		return nil, ErrContentNotFound
+		// unknown concepts are ignored when any concept can match, whether or not the others have content
+		conceptMissing := len(resolved) < len(conceptUUIDs)
+		if params.MatchAnyConcept {
+			conceptMissing = len(resolved) == 0
+		}
+		if conceptMissing {
+			return ContentPage{Concepts: resolved}, ErrConceptNotFound
+		}
+		return ContentPage{Concepts: resolved}, ErrContentNotFound
+	}
+
+	var nextCursor *Cursor
+	if len(results) > params.ContentLimit {
+		results = results[:params.ContentLimit]
+		last := results[len(results)-1]
+		nextCursor = &Cursor{Sort: sortName, Key: last.SortKey, Tie: last.TieKey, UUID: last.UUID}
+	}
+
+	selected := map[string]bool{}
+	for _, field := range params.Fields {
+		selected[field] = true
 	}
 
 	cntList := make([]Content, 0)
 	for _, result := range results {
-		cntList = append(cntList, Content{
+		cnt := Content{
 			ID:     ThingsPrefix + result.UUID, //Not using mapper as this has a different prefix (www.ft.com not api.ft.com)
 			APIURL: mapper.APIURL(result.UUID, result.Types, ""),
-		})
+		}
+		if selected["title"] {
+			cnt.Title = result.Title
+		}
+		if selected["publishedDate"] {
+			cnt.PublishedDate = result.PublishedDate
+		}
+		if selected["types"] {
+			cnt.Types = contentTypeURIs(result.Types)
+		}
+		if result.Expanded {
+			for _, conceptUUID := range result.ExpandedFrom {
+				cnt.ExpandedFrom = append(cnt.ExpandedFrom, mapper.IDURL(conceptUUID))
+			}
+		}
+		for _, ann := range result.Annotations {
+			cnt.Annotations = append(cnt.Annotations, Annotation{
+				Predicate:       relationshipPredicates[ann.RelType],
+				ConceptID:       mapper.IDURL(ann.LeafUUID),
+				Authority:       ann.Authority,
+				RelevanceScore:  ann.RelevanceScore,
+				ConfidenceScore: ann.ConfidenceScore,
+			})
+		}
+		cntList = append(cntList, cnt)
+	}
+
+	contentPage := ContentPage{Content: cntList, NextCursor: nextCursor, Concepts: resolved}
+	if len(totalResults) > 0 {
+		contentPage.Total = totalResults[0].Total
+	}
+	return contentPage, nil
+}
+
+// fieldsProjection returns the extra columns needed for the selected optional fields
+func fieldsProjection(fields []string) string {
+	var projection string
+	seen := map[string]bool{}
+	for _, field := range fields {
+		column := fieldProjections[field]
+		if column == "" || seen[column] {
+			continue
+		}
+		seen[column] = true
+		projection += ", " + column
+	}
+	return projection
+}
+
+// contentTypeURIs converts the labels of a content node to ontology type URIs
+func contentTypeURIs(labels []string) []string {
+	var types []string
+	for _, label := range labels {
+		if label == "Thing" {
+			continue
+		}
+		types = append(types, ContentOntologyPrefix+label)
+	}
+	sort.Strings(types)
+	return types
+}
+
+func whereClause(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
 	}
+	return " WHERE " + strings.Join(conditions, " AND ")
+}
+
+// resolveStatement matches the queried concepts, along with their canonical concept when they are concorded.
+// Concepts freshly written by the annotations are only labelled as Thing, so content is the only Thing left out.
+const resolveStatement = `
+			MATCH (queried:Thing)
+			WHERE queried.uuid IN {conceptUUIDs} AND NOT queried:Content
+			OPTIONAL MATCH (queried)-[:EQUIVALENT_TO]->(canonical:Concept)`
+
+func resolveQuery(conceptUUIDs []string, result *[]ResolvedConcept) *neoism.CypherQuery {
+	return &neoism.CypherQuery{
+		Statement:  resolveStatement + ` RETURN queried.uuid as uuid, canonical.prefUUID as canonicalUUID`,
+		Parameters: neoism.Props{"conceptUUIDs": conceptUUIDs},
+		Result:     result,
+	}
+}
+
+// subsidiaryDepth bounds how many levels of subsidiaries are included for an organisation
+const subsidiaryDepth = 5
+
+// expansionPatterns returns the patterns leading from a canonical concept to the concepts whose content
+// is returned alongside its own. The relationships sit between the source concepts,
+// so the expanded concepts are resolved through their own concordance.
+func expansionPatterns(params RequestParams) []string {
+	var patterns []string
+	if params.NarrowerDepth > 0 {
+		patterns = append(patterns, fmt.Sprintf(`(canon)<-[:EQUIVALENT_TO]-()<-[:HAS_PARENT|HAS_BROADER*1..%d]-()-[:EQUIVALENT_TO]->(expanded:Concept)`, params.NarrowerDepth))
+	}
+	if params.IncludeSubsidiaries {
+		patterns = append(patterns, fmt.Sprintf(`(canon)<-[:EQUIVALENT_TO]-()<-[:SUB_ORGANISATION_OF*1..%d]-()-[:EQUIVALENT_TO]->(expanded:Concept)`, subsidiaryDepth))
+	}
+	if params.IncludeMemberships {
+		patterns = append(patterns, `(canon)<-[:EQUIVALENT_TO]-()<-[:HAS_MEMBER]-()-[:HAS_ORGANISATION]->()-[:EQUIVALENT_TO]->(expanded:Concept)`)
+	}
+	return patterns
+}
+
+// expansionStatement lists each canonical concept as concept, followed by the concepts it expands to
+func expansionStatement(patterns []string, carriedColumns string) string {
+	statement := `
+			WITH ` + carriedColumns + `canons, canon, [canon] as concepts`
+	for _, pattern := range patterns {
+		statement += `
+			OPTIONAL MATCH ` + pattern + `
+			WITH ` + carriedColumns + `canons, canon, concepts + collect(DISTINCT expanded) as concepts`
+	}
+	return statement + `
+			UNWIND concepts as concept`
+}
 
-	return cntList, nil
+// relationshipFilter builds the relationship type filter for the annotation predicates,
+// an empty filter matches content annotated with any predicate.
+// Relationship types cannot be passed as query parameters, so only known predicates are used.
+func relationshipFilter(predicates []string) string {
+	var relTypes []string
+	seen := map[string]bool{}
+	for _, p := range predicates {
+		relType, ok := predicateRelationships[p]
+		if !ok || seen[relType] {
+			continue
+		}
+		seen[relType] = true
+		relTypes = append(relTypes, relType)
+	}
+	if len(relTypes) == 0 {
+		return ""
+	}
+	return ":" + strings.Join(relTypes, "|")
 }
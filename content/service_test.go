@@ -1,3 +1,4 @@
+//go:build integration
 // +build integration
 
 package content
@@ -6,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -72,12 +74,33 @@ func TestFindMatchingContentForV2Annotation(t *testing.T) {
 	defer cleanDB(t, MSJConceptUUID, contentUUID, FakebookConceptUUID)
 
 	contentByConceptDriver := &ConceptService{conn: db}
-	contentList, err := contentByConceptDriver.GetContentForConcept(MSJConceptUUID, RequestParams{0, defaultLimit, 0, 0})
+	contentPage, err := contentByConceptDriver.GetContentForConcepts([]string{MSJConceptUUID}, RequestParams{ContentLimit: defaultLimit})
+	contentList := contentPage.Content
 	assert.NoError(err, "Unexpected error for concept %s", MSJConceptUUID)
 	assert.Equal(1, len(contentList), "Didn't get the same list of content")
 	assertListContainsAll(assert, contentList, getExpectedContent())
 }
 
+func TestFindMatchingContentWithSelectedFields(t *testing.T) {
+	assert := assert.New(t)
+
+	writeContent(assert, db, contentUUID)
+	writeAnnotations(assert, db, contentUUID, "v2", "./fixtures/Annotations-3fc9fe3e-af8c-4f7f-961a-e5065392bb31-v2.json")
+	writeConcept(assert, db, "./fixtures/Organisation-MSJ-5d1510f8-2779-4b74-adab-0a5eb138fca6.json")
+
+	defer cleanDB(t, MSJConceptUUID, contentUUID, FakebookConceptUUID)
+
+	contentByConceptDriver := &ConceptService{conn: db}
+	contentPage, err := contentByConceptDriver.GetContentForConcepts([]string{MSJConceptUUID}, RequestParams{ContentLimit: defaultLimit, Fields: []string{"title", "publishedDate", "types"}})
+	assert.NoError(err, "Unexpected error for concept %s", MSJConceptUUID)
+
+	expected := getExpectedContent()
+	expected.Title = "Bitcoin story makes Newsweek the headline"
+	expected.PublishedDate = "2014-03-07T19:18:01.000Z"
+	expected.Types = []string{"http://www.ft.com/ontology/content/Content"}
+	assertListContainsAll(assert, contentPage.Content, expected)
+}
+
 func TestFindMatchingContentForV1Annotation(t *testing.T) {
 	assert := assert.New(t)
 
@@ -88,12 +111,145 @@ func TestFindMatchingContentForV1Annotation(t *testing.T) {
 	defer cleanDB(t, MSJConceptUUID, contentUUID, FakebookConceptUUID, MetalMickeyConceptUUID)
 
 	contentByConceptDriver := &ConceptService{conn: db}
-	contentList, err := contentByConceptDriver.GetContentForConcept(MetalMickeyConceptUUID, RequestParams{0, defaultLimit, 0, 0})
+	contentPage, err := contentByConceptDriver.GetContentForConcepts([]string{MetalMickeyConceptUUID}, RequestParams{ContentLimit: defaultLimit})
+	contentList := contentPage.Content
 	assert.NoError(err, "Unexpected error for concept %s", MetalMickeyConceptUUID)
 	assert.Equal(1, len(contentList), "Didn't get the same list of content")
 	assertListContainsAll(assert, contentList, getExpectedContent())
 }
 
+func TestFindContentAnnotatedWithAllConcepts(t *testing.T) {
+	assert := assert.New(t)
+
+	writeContent(assert, db, contentUUID)
+	writeAnnotations(assert, db, contentUUID, "v1", "./fixtures/Annotations-3fc9fe3e-af8c-4f7f-961a-e5065392bb31-v1.json")
+	writeAnnotations(assert, db, contentUUID, "v2", "./fixtures/Annotations-3fc9fe3e-af8c-4f7f-961a-e5065392bb31-v2.json")
+	writeConcept(assert, db, "./fixtures/Organisation-MSJ-5d1510f8-2779-4b74-adab-0a5eb138fca6.json")
+	writeConcept(assert, db, "./fixtures/Subject-MetalMickey-0483bef8-5797-40b8-9b25-b12e492f63c6.json")
+	writeConcept(assert, db, "./fixtures/Organisation-Fakebook-eac853f5-3859-4c08-8540-55e043719400.json")
+
+	defer cleanDB(t, MSJConceptUUID, contentUUID, FakebookConceptUUID, MetalMickeyConceptUUID)
+
+	contentByConceptDriver := &ConceptService{conn: db}
+	contentPage, err := contentByConceptDriver.GetContentForConcepts([]string{MSJConceptUUID, MetalMickeyConceptUUID}, RequestParams{ContentLimit: defaultLimit, IncludeTotal: true})
+	assert.NoError(err, "Unexpected error for concepts %s and %s", MSJConceptUUID, MetalMickeyConceptUUID)
+	assertListContainsAll(assert, contentPage.Content, getExpectedContent())
+	assert.Equal(1, contentPage.Total, "Didn't get the right total")
+
+	_, err = contentByConceptDriver.GetContentForConcepts([]string{MSJConceptUUID, FakebookConceptUUID}, RequestParams{ContentLimit: defaultLimit})
+	assert.Equal(ErrContentNotFound, err, "Found content for concepts %s and %s", MSJConceptUUID, FakebookConceptUUID)
+
+	contentPage, err = contentByConceptDriver.GetContentForConcepts([]string{MSJConceptUUID, OnyxPikeBrandUUID}, RequestParams{ContentLimit: defaultLimit})
+	assert.Equal(ErrConceptNotFound, err, "Found content although concept %s doesn't exist", OnyxPikeBrandUUID)
+	assert.Equal([]ResolvedConcept{{UUID: MSJConceptUUID, CanonicalUUID: MSJConceptUUID}}, contentPage.Concepts, "Didn't resolve the existing concept")
+}
+
+func TestContentAnnotatedWithAllConceptsIsPaginated(t *testing.T) {
+	assert := assert.New(t)
+
+	defer cleanDB(t, contentUUID, content2UUID, content3UUID, content4UUID, JohnSmithFSUUID, JohnSmithSmartlogicUUID, JohnSmithTMEUUID, JohnSmithOtherTMEUUID)
+
+	writeJohnSmithContent(assert)
+
+	contentByConceptDriver := &ConceptService{conn: db}
+	// both concepts resolve to the same canonical concept, all of its content is annotated with both of them
+	conceptUUIDs := []string{JohnSmithSmartlogicUUID, JohnSmithTMEUUID}
+
+	contentPage, err := contentByConceptDriver.GetContentForConcepts(conceptUUIDs, RequestParams{Page: 2, ContentLimit: 3})
+	assert.NoError(err, "Unexpected error for concepts %v", conceptUUIDs)
+	assert.Equal([]Content{{ID: "http://www.ft.com/things/" + content2UUID, APIURL: "http://api.ft.com/content/" + content2UUID}}, contentPage.Content, "Didn't get the last page")
+
+	requestParams := RequestParams{Page: defaultPage, ContentLimit: 3}
+	var uuids []string
+	for {
+		contentPage, err := contentByConceptDriver.GetContentForConcepts(conceptUUIDs, requestParams)
+		assert.NoError(err, "Unexpected error for concepts %v", conceptUUIDs)
+		for _, c := range contentPage.Content {
+			uuids = append(uuids, c.ID[len(ThingsPrefix):])
+		}
+		if contentPage.NextCursor == nil {
+			break
+		}
+		requestParams.Cursor = contentPage.NextCursor
+	}
+	assert.Equal([]string{content4UUID, contentUUID, content3UUID, content2UUID}, uuids, "Didn't get all the content across the cursor pages")
+}
+
+func TestFindContentAnnotatedWithAnyConcept(t *testing.T) {
+	assert := assert.New(t)
+
+	defer cleanDB(t, content2UUID, content3UUID, content4UUID, OnyxPikeBrandUUID, OnyxPikeParentBrandUUID, OnyPikeyRightBrandUUID)
+
+	writeContent(assert, db, content2UUID)
+	writeContent(assert, db, content3UUID)
+	writeContent(assert, db, content4UUID)
+	writeAnnotations(assert, db, content2UUID, "v2", "./fixtures/Annotations-bfa97890-76ff-4a35-a775-b8768f7ea383-V2.json")
+	writeAnnotations(assert, db, content3UUID, "v2", "./fixtures/Annotations-5a9c7429-e76b-4f37-b5d1-842d64a45167-V2.json")
+	writeAnnotations(assert, db, content4UUID, "v2", "./fixtures/Annotations-8e193b84-4697-41aa-a480-065831d1d964-V2.json")
+	writeConcept(assert, db, "./fixtures/Brand-OnyxPike-9a07c16f-def0-457d-a04a-57ba68ba1e00.json")
+	writeConcept(assert, db, "./fixtures/Brand-OnyxPikeParent-0635a44c-2e9e-49b6-b078-be53b0e5301b.json")
+
+	contentByConceptDriver := &ConceptService{conn: db}
+	conceptUUIDs := []string{OnyxPikeBrandUUID, OnyxPikeParentBrandUUID, MSJConceptUUID}
+
+	// the unknown concept is ignored and the content of the other ones is merged in publish date order
+	requestParams := RequestParams{Page: defaultPage, ContentLimit: 1, MatchAnyConcept: true}
+	var allContent []Content
+	for {
+		contentPage, err := contentByConceptDriver.GetContentForConcepts(conceptUUIDs, requestParams)
+		assert.NoError(err, "Unexpected error for concepts %v", conceptUUIDs)
+		allContent = append(allContent, contentPage.Content...)
+		if contentPage.NextCursor == nil {
+			break
+		}
+		requestParams.Cursor = contentPage.NextCursor
+	}
+
+	assert.Equal([]Content{
+		{ID: "http://www.ft.com/things/" + content4UUID, APIURL: "http://api.ft.com/content/" + content4UUID},
+		{ID: "http://www.ft.com/things/" + content3UUID, APIURL: "http://api.ft.com/content/" + content3UUID},
+		{ID: "http://www.ft.com/things/" + content2UUID, APIURL: "http://api.ft.com/content/" + content2UUID},
+	}, allContent)
+}
+
+func TestUnknownConceptsAreIgnoredWhenAnyConceptMatches(t *testing.T) {
+	assert := assert.New(t)
+
+	defer cleanDB(t, OnyxPikeBrandUUID, OnyxPikeParentBrandUUID, OnyPikeyRightBrandUUID)
+
+	writeConcept(assert, db, "./fixtures/Brand-OnyxPike-9a07c16f-def0-457d-a04a-57ba68ba1e00.json")
+
+	contentByConceptDriver := &ConceptService{conn: db}
+	requestParams := RequestParams{Page: defaultPage, ContentLimit: defaultLimit, MatchAnyConcept: true}
+
+	contentPage, err := contentByConceptDriver.GetContentForConcepts([]string{OnyxPikeBrandUUID, MSJConceptUUID}, requestParams)
+	assert.Equal(ErrContentNotFound, err, "Unknown concept %s wasn't ignored", MSJConceptUUID)
+	assert.Equal([]ResolvedConcept{{UUID: OnyxPikeBrandUUID, CanonicalUUID: OnyxPikeBrandUUID}}, contentPage.Concepts, "Didn't resolve the existing concept")
+
+	_, err = contentByConceptDriver.GetContentForConcepts([]string{MSJConceptUUID, FakebookConceptUUID}, requestParams)
+	assert.Equal(ErrConceptNotFound, err, "Found concepts %s and %s although they don't exist", MSJConceptUUID, FakebookConceptUUID)
+}
+
+func TestContentAnnotatedWithExcludedConceptsIsRemoved(t *testing.T) {
+	assert := assert.New(t)
+
+	writeContent(assert, db, contentUUID)
+	writeAnnotations(assert, db, contentUUID, "v1", "./fixtures/Annotations-3fc9fe3e-af8c-4f7f-961a-e5065392bb31-v1.json")
+	writeAnnotations(assert, db, contentUUID, "v2", "./fixtures/Annotations-3fc9fe3e-af8c-4f7f-961a-e5065392bb31-v2.json")
+	writeConcept(assert, db, "./fixtures/Organisation-MSJ-5d1510f8-2779-4b74-adab-0a5eb138fca6.json")
+	writeConcept(assert, db, "./fixtures/Subject-MetalMickey-0483bef8-5797-40b8-9b25-b12e492f63c6.json")
+
+	defer cleanDB(t, MSJConceptUUID, contentUUID, FakebookConceptUUID, MetalMickeyConceptUUID)
+
+	contentByConceptDriver := &ConceptService{conn: db}
+	_, err := contentByConceptDriver.GetContentForConcepts([]string{MSJConceptUUID}, RequestParams{ContentLimit: defaultLimit, ExcludedConceptUUIDs: []string{MetalMickeyConceptUUID}})
+	assert.Equal(ErrContentNotFound, err, "Found content annotated with excluded concept %s", MetalMickeyConceptUUID)
+
+	contentPage, err := contentByConceptDriver.GetContentForConcepts([]string{MSJConceptUUID}, RequestParams{ContentLimit: defaultLimit, ExcludedConceptUUIDs: []string{FakebookConceptUUID}})
+	assert.NoError(err, "Unexpected error for concept %s", MSJConceptUUID)
+	assertListContainsAll(assert, contentPage.Content, getExpectedContent())
+}
+
 func TestFindMatchingContentForV2AnnotationWithLimit(t *testing.T) {
 	assert := assert.New(t)
 
@@ -105,7 +261,8 @@ func TestFindMatchingContentForV2AnnotationWithLimit(t *testing.T) {
 	defer cleanDB(t, MSJConceptUUID, contentUUID, FakebookConceptUUID, content2UUID)
 
 	contentByConceptDriver := &ConceptService{conn: db}
-	contentList, err := contentByConceptDriver.GetContentForConcept(MSJConceptUUID, RequestParams{0, 1, 0, 0})
+	contentPage, err := contentByConceptDriver.GetContentForConcepts([]string{MSJConceptUUID}, RequestParams{ContentLimit: 1})
+	contentList := contentPage.Content
 	assert.NoError(err, "Unexpected error for concept %s", MSJConceptUUID)
 	assert.Equal(1, len(contentList), "Didn't get the same list of content")
 	assertListContainsAll(assert, contentList, getExpectedContent())
@@ -123,7 +280,8 @@ func TestRetrieveNoContentForV1AnnotationForExclusiveDatePeriod(t *testing.T) {
 	contentByConceptDriver := &ConceptService{conn: db}
 	fromDate, _ := time.Parse("2006-01-02", "2014-03-08")
 	toDate, _ := time.Parse("2006-01-02", "2014-03-09")
-	contentList, err := contentByConceptDriver.GetContentForConcept(MetalMickeyConceptUUID, RequestParams{0, defaultLimit, fromDate.Unix(), toDate.Unix()})
+	contentPage, err := contentByConceptDriver.GetContentForConcepts([]string{MetalMickeyConceptUUID}, RequestParams{ContentLimit: defaultLimit, FromDateEpoch: fromDate.Unix(), ToDateEpoch: toDate.Unix()})
+	contentList := contentPage.Content
 	assert.Equal(ErrContentNotFound, err, "Found matching content for concept %s", MetalMickeyConceptUUID)
 	assert.Equal(0, len(contentList), "Should not get any content items")
 }
@@ -132,11 +290,13 @@ func TestRetrieveNoContentWhenThereAreNoContentForThatConcept(t *testing.T) {
 	assert := assert.New(t)
 
 	writeContent(assert, db, contentUUID)
+	writeConcept(assert, db, "./fixtures/Organisation-MSJ-5d1510f8-2779-4b74-adab-0a5eb138fca6.json")
 
 	defer cleanDB(t, MSJConceptUUID, contentUUID, FakebookConceptUUID)
 
 	contentByConceptDriver := &ConceptService{conn: db}
-	content, err := contentByConceptDriver.GetContentForConcept(MSJConceptUUID, RequestParams{0, defaultLimit, 0, 0})
+	contentPage, err := contentByConceptDriver.GetContentForConcepts([]string{MSJConceptUUID}, RequestParams{ContentLimit: defaultLimit})
+	content := contentPage.Content
 	assert.Equal(ErrContentNotFound, err, "Found matching content for concept %s", MetalMickeyConceptUUID)
 	assert.Equal(0, len(content), "Should not get any content items")
 }
@@ -151,8 +311,9 @@ func TestRetrieveNoContentWhenThereAreNoConceptsPresent(t *testing.T) {
 	defer cleanDB(t, content2UUID, MSJConceptUUID, contentUUID, MetalMickeyConceptUUID, FakebookConceptUUID)
 
 	contentByConceptDriver := &ConceptService{conn: db}
-	contentList, err := contentByConceptDriver.GetContentForConcept(MSJConceptUUID, RequestParams{0, defaultLimit, 0, 0})
-	assert.Equal(ErrContentNotFound, err, "Found matching content for concept %s", MetalMickeyConceptUUID)
+	contentPage, err := contentByConceptDriver.GetContentForConcepts([]string{FakebookConceptUUID}, RequestParams{ContentLimit: defaultLimit})
+	contentList := contentPage.Content
+	assert.Equal(ErrConceptNotFound, err, "Found matching content for concept %s", FakebookConceptUUID)
 	assert.Equal(0, len(contentList), "Didn't get the right number of content items, content=%s", contentList)
 }
 
@@ -172,11 +333,87 @@ func TestBrandsDontReturnParentContent(t *testing.T) {
 	writeConcept(assert, db, fmt.Sprintf("./fixtures/Brand-OnyxPikeParent-%v.json", OnyxPikeParentBrandUUID))
 
 	contentByConceptDriver := &ConceptService{conn: db}
-	contentList, err := contentByConceptDriver.GetContentForConcept(OnyxPikeBrandUUID, RequestParams{0, defaultLimit, 0, 0})
+	contentPage, err := contentByConceptDriver.GetContentForConcepts([]string{OnyxPikeBrandUUID}, RequestParams{ContentLimit: defaultLimit})
+	contentList := contentPage.Content
 	assert.NoError(err, "Unexpected error for concept %s", OnyxPikeBrandUUID)
 	assert.Equal(2, len(contentList), "Didn't get the right number of content items, content=%s", contentList)
 }
 
+func TestBrandsReturnNarrowerContentWhenRequested(t *testing.T) {
+	assert := assert.New(t)
+	defer cleanDB(t, content2UUID, content3UUID, content4UUID, OnyxPikeBrandUUID, OnyxPikeParentBrandUUID, OnyPikeyRightBrandUUID)
+
+	writeContent(assert, db, content2UUID)
+	writeContent(assert, db, content3UUID)
+	writeContent(assert, db, content4UUID)
+
+	writeAnnotations(assert, db, content2UUID, "v2", fmt.Sprintf("./fixtures/Annotations-%v-V2.json", content2UUID))
+	writeAnnotations(assert, db, content3UUID, "v2", fmt.Sprintf("./fixtures/Annotations-%v-V2.json", content3UUID))
+	writeAnnotations(assert, db, content4UUID, "v2", fmt.Sprintf("./fixtures/Annotations-%v-V2.json", content4UUID))
+
+	writeConcept(assert, db, fmt.Sprintf("./fixtures/Brand-OnyxPike-%v.json", OnyxPikeBrandUUID))
+	writeConcept(assert, db, fmt.Sprintf("./fixtures/Brand-OnyxPikeParent-%v.json", OnyxPikeParentBrandUUID))
+
+	contentByConceptDriver := &ConceptService{conn: db}
+	contentPage, err := contentByConceptDriver.GetContentForConcepts([]string{OnyxPikeParentBrandUUID}, RequestParams{ContentLimit: defaultLimit})
+	assert.NoError(err, "Unexpected error for concept %s", OnyxPikeParentBrandUUID)
+	assert.Equal(1, len(contentPage.Content), "Didn't get the right number of content items, content=%s", contentPage.Content)
+
+	contentPage, err = contentByConceptDriver.GetContentForConcepts([]string{OnyxPikeParentBrandUUID}, RequestParams{ContentLimit: defaultLimit, NarrowerDepth: 1, IncludeTotal: true})
+	assert.NoError(err, "Unexpected error for concept %s", OnyxPikeParentBrandUUID)
+	assert.Equal(3, len(contentPage.Content), "Didn't get the right number of content items, content=%s", contentPage.Content)
+	assert.Equal(3, contentPage.Total, "Didn't get the right total")
+}
+
+func TestOrganisationsReturnSubsidiaryContentWhenRequested(t *testing.T) {
+	assert := assert.New(t)
+
+	writeContent(assert, db, contentUUID)
+	writeAnnotations(assert, db, contentUUID, "v2", "./fixtures/Annotations-3fc9fe3e-af8c-4f7f-961a-e5065392bb31-v2.json")
+	writeConcept(assert, db, "./fixtures/Organisation-MSJ-Subsidiary-5d1510f8-2779-4b74-adab-0a5eb138fca6.json")
+	writeConcept(assert, db, "./fixtures/Organisation-Fakebook-eac853f5-3859-4c08-8540-55e043719400.json")
+
+	defer cleanDB(t, MSJConceptUUID, contentUUID, FakebookConceptUUID)
+
+	contentByConceptDriver := &ConceptService{conn: db}
+	_, err := contentByConceptDriver.GetContentForConcepts([]string{FakebookConceptUUID}, RequestParams{ContentLimit: defaultLimit})
+	assert.Equal(ErrContentNotFound, err, "Found subsidiary content for concept %s", FakebookConceptUUID)
+
+	contentPage, err := contentByConceptDriver.GetContentForConcepts([]string{FakebookConceptUUID}, RequestParams{ContentLimit: defaultLimit, IncludeSubsidiaries: true})
+	assert.NoError(err, "Unexpected error for concept %s", FakebookConceptUUID)
+	expected := getExpectedContent()
+	expected.ExpandedFrom = []string{"http://api.ft.com/things/" + MSJConceptUUID}
+	assertListContainsAll(assert, contentPage.Content, expected)
+
+	contentPage, err = contentByConceptDriver.GetContentForConcepts([]string{MSJConceptUUID}, RequestParams{ContentLimit: defaultLimit, IncludeSubsidiaries: true})
+	assert.NoError(err, "Unexpected error for concept %s", MSJConceptUUID)
+	assertListContainsAll(assert, contentPage.Content, getExpectedContent())
+}
+
+func TestContentIsReturnedForConceptWithoutConcordance(t *testing.T) {
+	assert := assert.New(t)
+
+	// the annotations create the concept without concordance
+	writeContent(assert, db, contentUUID)
+	writeAnnotations(assert, db, contentUUID, "v2", "./fixtures/Annotations-3fc9fe3e-af8c-4f7f-961a-e5065392bb31-v2.json")
+
+	defer cleanDB(t, MSJConceptUUID, contentUUID)
+
+	contentByConceptDriver := &ConceptService{conn: db}
+	contentPage, err := contentByConceptDriver.GetContentForConcepts([]string{MSJConceptUUID}, RequestParams{ContentLimit: defaultLimit})
+	assert.NoError(err, "Unexpected error for concept %s", MSJConceptUUID)
+	assertListContainsAll(assert, contentPage.Content, getExpectedContent())
+	assert.Equal([]ResolvedConcept{{UUID: MSJConceptUUID}}, contentPage.Concepts)
+	assert.False(contentPage.Concorded(), "Concept %s should not be concorded", MSJConceptUUID)
+
+	writeConcept(assert, db, "./fixtures/Organisation-MSJ-5d1510f8-2779-4b74-adab-0a5eb138fca6.json")
+
+	contentPage, err = contentByConceptDriver.GetContentForConcepts([]string{MSJConceptUUID}, RequestParams{ContentLimit: defaultLimit})
+	assert.NoError(err, "Unexpected error for concept %s", MSJConceptUUID)
+	assertListContainsAll(assert, contentPage.Content, getExpectedContent())
+	assert.True(contentPage.Concorded(), "Concept %s should be concorded", MSJConceptUUID)
+}
+
 func TestContentIsReturnedFromAllLeafNodesOfConcordance(t *testing.T) {
 	assert := assert.New(t)
 
@@ -199,7 +436,8 @@ func TestContentIsReturnedFromAllLeafNodesOfConcordance(t *testing.T) {
 	idsToCheck := []string{JohnSmithFSUUID, JohnSmithSmartlogicUUID, JohnSmithTMEUUID, JohnSmithOtherTMEUUID}
 
 	for _, uuid := range idsToCheck {
-		contentList, err := contentByConceptDriver.GetContentForConcept(uuid, RequestParams{0, defaultLimit, 0, 0})
+		contentPage, err := contentByConceptDriver.GetContentForConcepts([]string{uuid}, RequestParams{ContentLimit: defaultLimit})
+		contentList := contentPage.Content
 		assert.NoError(err, "Unexpected error for concept %s", uuid)
 		assert.Equal(4, len(contentList), "Didn't get the right number of content items, content=%s", contentList)
 	}
@@ -227,13 +465,60 @@ func TestContentIsReturnedFromAllLeafNodesOfConcordanceWithDateRestrictions(t *t
 	idsToCheck := []string{JohnSmithFSUUID, JohnSmithSmartlogicUUID, JohnSmithTMEUUID, JohnSmithOtherTMEUUID}
 
 	for _, uuid := range idsToCheck {
-		contentList, err := contentByConceptDriver.GetContentForConcept(uuid, RequestParams{0, defaultLimit, 1372550400, 1388448000})
+		contentPage, err := contentByConceptDriver.GetContentForConcepts([]string{uuid}, RequestParams{ContentLimit: defaultLimit, FromDateEpoch: 1372550400, ToDateEpoch: 1388448000})
+		contentList := contentPage.Content
 		//From July 1st 2013 - January 1st 2014
 		assert.NoError(err, "Unexpected error for concept %s", uuid)
 		assert.Equal(1, len(contentList), "Didn't get the right number of content items, content=%s", contentList)
 	}
 }
 
+func TestContentIsReturnedFromAllLeafNodesOfConcordanceWithHalfOpenDateRanges(t *testing.T) {
+	assert := assert.New(t)
+
+	defer cleanDB(t, contentUUID, content2UUID, content3UUID, content4UUID, JohnSmithFSUUID, JohnSmithSmartlogicUUID, JohnSmithTMEUUID, JohnSmithOtherTMEUUID)
+
+	writeJohnSmithContent(assert)
+
+	contentByConceptDriver := &ConceptService{conn: db}
+
+	//From July 1st 2013
+	contentPage, err := contentByConceptDriver.GetContentForConcepts([]string{JohnSmithSmartlogicUUID}, RequestParams{ContentLimit: defaultLimit, FromDateEpoch: 1372550400})
+	contentList := contentPage.Content
+	assert.NoError(err, "Unexpected error for concept %s", JohnSmithSmartlogicUUID)
+	assert.Equal(3, len(contentList), "Didn't get the right number of content items, content=%s", contentList)
+
+	//Until July 1st 2013
+	contentPage, err = contentByConceptDriver.GetContentForConcepts([]string{JohnSmithSmartlogicUUID}, RequestParams{ContentLimit: defaultLimit, ToDateEpoch: 1372550400})
+	contentList = contentPage.Content
+	assert.NoError(err, "Unexpected error for concept %s", JohnSmithSmartlogicUUID)
+	assert.Equal(1, len(contentList), "Didn't get the right number of content items, content=%s", contentList)
+}
+
+func TestContentIsReturnedFromAllLeafNodesOfConcordanceWithInclusiveDateBounds(t *testing.T) {
+	assert := assert.New(t)
+
+	defer cleanDB(t, contentUUID, content2UUID, content3UUID, content4UUID, JohnSmithFSUUID, JohnSmithSmartlogicUUID, JohnSmithTMEUUID, JohnSmithOtherTMEUUID)
+
+	writeJohnSmithContent(assert)
+
+	contentByConceptDriver := &ConceptService{conn: db}
+
+	publishedDate, _ := time.Parse(time.RFC3339, "2013-09-07T19:18:01.000Z")
+	params := RequestParams{ContentLimit: defaultLimit, FromDateEpoch: publishedDate.Unix(), ToDateEpoch: publishedDate.Unix()}
+
+	contentPage, err := contentByConceptDriver.GetContentForConcepts([]string{JohnSmithSmartlogicUUID}, params)
+	contentList := contentPage.Content
+	assert.Equal(ErrContentNotFound, err, "Found matching content for exclusive bounds")
+	assert.Equal(0, len(contentList), "Should not get any content items")
+
+	params.InclusiveDateBounds = true
+	contentPage, err = contentByConceptDriver.GetContentForConcepts([]string{JohnSmithSmartlogicUUID}, params)
+	contentList = contentPage.Content
+	assert.NoError(err, "Unexpected error for concept %s", JohnSmithSmartlogicUUID)
+	assert.Equal(1, len(contentList), "Didn't get the right number of content items, content=%s", contentList)
+}
+
 func TestContentIsReturnedFromAllLeafNodesOfConcordanceWithPagination(t *testing.T) {
 	assert := assert.New(t)
 
@@ -265,7 +550,8 @@ func TestContentIsReturnedFromAllLeafNodesOfConcordanceWithPagination(t *testing
 				ContentLimit: pageSize,
 			}
 
-			pageContents, err := contentByConceptDriver.GetContentForConcept(uuid, requestParams)
+			contentPage, err := contentByConceptDriver.GetContentForConcepts([]string{uuid}, requestParams)
+			pageContents := contentPage.Content
 			if err == ErrContentNotFound {
 				break
 			}
@@ -281,6 +567,344 @@ func TestContentIsReturnedFromAllLeafNodesOfConcordanceWithPagination(t *testing
 	}
 }
 
+func TestContentIsReturnedFromAllLeafNodesOfConcordanceWithCursorPagination(t *testing.T) {
+	assert := assert.New(t)
+
+	defer cleanDB(t, contentUUID, content2UUID, content3UUID, content4UUID, JohnSmithFSUUID, JohnSmithSmartlogicUUID, JohnSmithTMEUUID, JohnSmithOtherTMEUUID)
+
+	writeJohnSmithContent(assert)
+
+	contentByConceptDriver := &ConceptService{conn: db}
+
+	pageSize := 3
+	requestParams := RequestParams{Page: defaultPage, ContentLimit: pageSize}
+	allContent := make([]Content, 0)
+	pages := 0
+	for {
+		contentPage, err := contentByConceptDriver.GetContentForConcepts([]string{JohnSmithSmartlogicUUID}, requestParams)
+		assert.NoError(err, "Unexpected error for concept %s", JohnSmithSmartlogicUUID)
+		pages++
+		allContent = append(allContent, contentPage.Content...)
+		if contentPage.NextCursor == nil {
+			break
+		}
+		assert.Equal(pageSize, len(contentPage.Content), "Didn't get the right number of page items, content=%s", contentPage.Content)
+		requestParams.Cursor = contentPage.NextCursor
+	}
+
+	assert.Equal(2, pages, "Didn't get the right number of pages")
+	assert.Equal(4, len(allContent), "Didn't get the right number of content items, content=%s", allContent)
+	assertListContainsAll(assert, allContent, getJohnSmithContent()...)
+}
+
+func TestContentIsSortedInTheRequestedOrder(t *testing.T) {
+	assert := assert.New(t)
+
+	defer cleanDB(t, contentUUID, content2UUID, content3UUID, content4UUID, JohnSmithFSUUID, JohnSmithSmartlogicUUID, JohnSmithTMEUUID, JohnSmithOtherTMEUUID)
+
+	writeJohnSmithContent(assert)
+
+	contentByConceptDriver := &ConceptService{conn: db}
+
+	tests := []struct {
+		sort          string
+		expectedUUIDs []string
+	}{
+		{"", []string{content4UUID, contentUUID, content3UUID, content2UUID}},
+		{"-publishedDate", []string{content2UUID, content3UUID, contentUUID, content4UUID}},
+		// all the annotations have the same relevance, so the uuid breaks the ties
+	}
+
+	for _, test := range tests {
+		requestParams := RequestParams{Page: defaultPage, ContentLimit: 3, Sort: test.sort}
+		uuids := contentUUIDsInOrder(assert, contentByConceptDriver, JohnSmithSmartlogicUUID, requestParams)
+		assert.Equal(test.expectedUUIDs, uuids, "Didn't get the content in the right order for sort %s", test.sort)
+	}
+}
+
+func TestContentIsSortedByAnnotationDate(t *testing.T) {
+	assert := assert.New(t)
+
+	defer cleanDB(t, contentUUID, content2UUID, content3UUID, content4UUID, JohnSmithFSUUID, JohnSmithSmartlogicUUID, JohnSmithTMEUUID, JohnSmithOtherTMEUUID)
+
+	writeContent(assert, db, contentUUID)
+	writeContent(assert, db, content2UUID)
+	writeContent(assert, db, content3UUID)
+	writeContent(assert, db, content4UUID)
+
+	// the content is annotated in the opposite order to the one it was published in
+	writeAnnotations(assert, db, contentUUID, "v2", "./fixtures/Annotations-JohnSmith1-AnnotatedDate-v2.json")
+	writeAnnotations(assert, db, content2UUID, "v2", "./fixtures/Annotations-JohnSmith2-AnnotatedDate-v2.json")
+	writeAnnotations(assert, db, content3UUID, "v2", "./fixtures/Annotations-JohnSmith3-AnnotatedDate-v2.json")
+	writeAnnotations(assert, db, content4UUID, "v2", "./fixtures/Annotations-JohnSmith4-AnnotatedDate-v2.json")
+
+	writeConcept(assert, db, "./fixtures/Person-JohnSmith-f25b0f71-4cf9-4e3a-8510-14e86d922bfe.json")
+
+	contentByConceptDriver := &ConceptService{conn: db}
+
+	requestParams := RequestParams{Page: defaultPage, ContentLimit: 3, Sort: "annotatedDate"}
+	uuids := contentUUIDsInOrder(assert, contentByConceptDriver, JohnSmithSmartlogicUUID, requestParams)
+	assert.Equal([]string{content2UUID, content3UUID, contentUUID, content4UUID}, uuids, "Didn't get the most recently annotated content first")
+}
+
+func TestContentIsSortedByRelevance(t *testing.T) {
+	assert := assert.New(t)
+
+	defer cleanDB(t, contentUUID, content2UUID, content3UUID, content4UUID, JohnSmithFSUUID, JohnSmithSmartlogicUUID, JohnSmithTMEUUID, JohnSmithOtherTMEUUID)
+
+	writeContent(assert, db, contentUUID)
+	writeContent(assert, db, content2UUID)
+	writeContent(assert, db, content3UUID)
+	writeContent(assert, db, content4UUID)
+
+	// content and content3 share a relevance score, content2 is the oldest but the most relevant
+	writeAnnotations(assert, db, contentUUID, "v2", "./fixtures/Annotations-JohnSmith1-Relevance-v2.json")
+	writeAnnotations(assert, db, content2UUID, "v2", "./fixtures/Annotations-JohnSmith2-Relevance-v2.json")
+	writeAnnotations(assert, db, content3UUID, "v2", "./fixtures/Annotations-JohnSmith3-Relevance-v2.json")
+	writeAnnotations(assert, db, content4UUID, "v2", "./fixtures/Annotations-JohnSmith4-Relevance-v2.json")
+
+	writeConcept(assert, db, "./fixtures/Person-JohnSmith-f25b0f71-4cf9-4e3a-8510-14e86d922bfe.json")
+
+	contentByConceptDriver := &ConceptService{conn: db}
+
+	tests := []struct {
+		sort          string
+		halfLife      time.Duration
+		expectedUUIDs []string
+	}{
+		// equal scores are returned newest first, not in uuid order
+		{"relevance", 0, []string{content2UUID, contentUUID, content3UUID, content4UUID}},
+		// with a year long half-life a year of age is worth halving the score
+		{"recency-relevance", 365 * 24 * time.Hour, []string{contentUUID, content2UUID, content3UUID, content4UUID}},
+	}
+
+	for _, test := range tests {
+		requestParams := RequestParams{Page: defaultPage, ContentLimit: 1, Sort: test.sort, RelevanceHalfLife: test.halfLife}
+		uuids := contentUUIDsInOrder(assert, contentByConceptDriver, JohnSmithSmartlogicUUID, requestParams)
+		assert.Equal(test.expectedUUIDs, uuids, "Didn't get the content in the right order for sort %s", test.sort)
+	}
+}
+
+// contentUUIDsInOrder follows the cursors of all the pages of the content of a concept
+func contentUUIDsInOrder(assert *assert.Assertions, contentByConceptDriver *ConceptService, conceptUUID string, requestParams RequestParams) []string {
+	var uuids []string
+	for {
+		contentPage, err := contentByConceptDriver.GetContentForConcepts([]string{conceptUUID}, requestParams)
+		assert.NoError(err, "Unexpected error for sort %s", requestParams.Sort)
+		for _, c := range contentPage.Content {
+			uuids = append(uuids, c.ID[len(ThingsPrefix):])
+		}
+		if contentPage.NextCursor == nil {
+			return uuids
+		}
+		requestParams.Cursor = contentPage.NextCursor
+	}
+}
+
+func TestTotalIsReturnedForAllPages(t *testing.T) {
+	assert := assert.New(t)
+
+	defer cleanDB(t, contentUUID, content2UUID, content3UUID, content4UUID, JohnSmithFSUUID, JohnSmithSmartlogicUUID, JohnSmithTMEUUID, JohnSmithOtherTMEUUID)
+
+	writeJohnSmithContent(assert)
+
+	contentByConceptDriver := &ConceptService{conn: db}
+
+	contentPage, err := contentByConceptDriver.GetContentForConcepts([]string{JohnSmithSmartlogicUUID}, RequestParams{Page: 2, ContentLimit: 3, IncludeTotal: true})
+	assert.NoError(err, "Unexpected error for concept %s", JohnSmithSmartlogicUUID)
+	assert.Equal(1, len(contentPage.Content), "Didn't get the right number of page items, content=%s", contentPage.Content)
+	assert.Equal(4, contentPage.Total, "Didn't get the right total")
+
+	contentPage, err = contentByConceptDriver.GetContentForConcepts([]string{JohnSmithSmartlogicUUID}, RequestParams{Page: 1, ContentLimit: 3})
+	assert.NoError(err, "Unexpected error for concept %s", JohnSmithSmartlogicUUID)
+	assert.Equal(0, contentPage.Total, "Total should only be counted when requested")
+}
+
+func TestContentIsFilteredByAnnotationPredicate(t *testing.T) {
+	assert := assert.New(t)
+
+	defer cleanDB(t, contentUUID, content2UUID, content3UUID, content4UUID, JohnSmithFSUUID, JohnSmithSmartlogicUUID, JohnSmithTMEUUID, JohnSmithOtherTMEUUID)
+
+	writeJohnSmithContent(assert)
+
+	contentByConceptDriver := &ConceptService{conn: db}
+
+	tests := []struct {
+		predicates    []string
+		expectedCount int
+	}{
+		{[]string{"about"}, 1},
+		{[]string{"mentions"}, 2},
+		{[]string{"about", "isClassifiedBy"}, 2},
+		{[]string{"majorMentions"}, 0},
+	}
+
+	for _, test := range tests {
+		contentPage, err := contentByConceptDriver.GetContentForConcepts([]string{JohnSmithSmartlogicUUID}, RequestParams{ContentLimit: defaultLimit, Predicates: test.predicates})
+		contentList := contentPage.Content
+		if test.expectedCount == 0 {
+			assert.Equal(ErrContentNotFound, err, "Found matching content for predicates %v", test.predicates)
+			continue
+		}
+		assert.NoError(err, "Unexpected error for predicates %v", test.predicates)
+		assert.Equal(test.expectedCount, len(contentList), "Didn't get the right number of content items for predicates %v, content=%s", test.predicates, contentList)
+	}
+}
+
+func TestContentIsFilteredByContentType(t *testing.T) {
+	assert := assert.New(t)
+
+	defer cleanDB(t, contentUUID, content2UUID, content3UUID, content4UUID, JohnSmithFSUUID, JohnSmithSmartlogicUUID, JohnSmithTMEUUID, JohnSmithOtherTMEUUID)
+
+	writeJohnSmithContent(assert)
+
+	contentByConceptDriver := &ConceptService{conn: db}
+
+	contentPage, err := contentByConceptDriver.GetContentForConcepts([]string{JohnSmithSmartlogicUUID}, RequestParams{ContentLimit: defaultLimit, ExcludedContentTypes: []string{"ContentPackage"}})
+	contentList := contentPage.Content
+	assert.NoError(err, "Unexpected error for concept %s", JohnSmithSmartlogicUUID)
+	assert.Equal(4, len(contentList), "Didn't get the right number of content items, content=%s", contentList)
+
+	contentPage, err = contentByConceptDriver.GetContentForConcepts([]string{JohnSmithSmartlogicUUID}, RequestParams{ContentLimit: defaultLimit, ContentTypes: []string{"ContentPackage"}})
+	contentList = contentPage.Content
+	assert.Equal(ErrContentNotFound, err, "Found matching content for concept %s", JohnSmithSmartlogicUUID)
+	assert.Equal(0, len(contentList), "Should not get any content items")
+}
+
+func TestContentIsFilteredByMinimumScores(t *testing.T) {
+	assert := assert.New(t)
+
+	defer cleanDB(t, contentUUID, content2UUID, content3UUID, content4UUID, JohnSmithFSUUID, JohnSmithSmartlogicUUID, JohnSmithTMEUUID, JohnSmithOtherTMEUUID)
+
+	writeJohnSmithContent(assert)
+
+	contentByConceptDriver := &ConceptService{conn: db}
+
+	tests := []struct {
+		minRelevance  float64
+		minConfidence float64
+		expectedCount int
+	}{
+		{0.8, 0.99, 4},
+		{0.9, 0, 0},
+		{0, 1, 0},
+	}
+
+	for _, test := range tests {
+		contentPage, err := contentByConceptDriver.GetContentForConcepts([]string{JohnSmithSmartlogicUUID}, RequestParams{ContentLimit: defaultLimit, MinRelevance: test.minRelevance, MinConfidence: test.minConfidence})
+		if test.expectedCount == 0 {
+			assert.Equal(ErrContentNotFound, err, "Found matching content for minimum scores %v and %v", test.minRelevance, test.minConfidence)
+			continue
+		}
+		assert.NoError(err, "Unexpected error for minimum scores %v and %v", test.minRelevance, test.minConfidence)
+		assert.Equal(test.expectedCount, len(contentPage.Content), "Didn't get the right number of content items, content=%s", contentPage.Content)
+	}
+}
+
+func TestAnnotationsAreReturnedWhenRequested(t *testing.T) {
+	assert := assert.New(t)
+
+	defer cleanDB(t, contentUUID, content2UUID, content3UUID, content4UUID, JohnSmithFSUUID, JohnSmithSmartlogicUUID, JohnSmithTMEUUID, JohnSmithOtherTMEUUID)
+
+	writeJohnSmithContent(assert)
+
+	contentByConceptDriver := &ConceptService{conn: db}
+
+	contentPage, err := contentByConceptDriver.GetContentForConcepts([]string{JohnSmithFSUUID}, RequestParams{ContentLimit: defaultLimit, Predicates: []string{"about"}, IncludeAnnotations: true})
+	assert.NoError(err, "Unexpected error for concept %s", JohnSmithFSUUID)
+	assert.Equal(1, len(contentPage.Content), "Didn't get the right number of content items, content=%s", contentPage.Content)
+
+	relevance, confidence := 0.8, 0.99
+	assert.Equal([]Annotation{{
+		Predicate:       "about",
+		ConceptID:       "http://api.ft.com/things/" + JohnSmithOtherTMEUUID,
+		Authority:       "TME",
+		RelevanceScore:  &relevance,
+		ConfidenceScore: &confidence,
+	}}, contentPage.Content[0].Annotations)
+}
+
+func TestConceptsAreResolvedToTheirCanonicalConcept(t *testing.T) {
+	assert := assert.New(t)
+
+	defer cleanDB(t, contentUUID, content2UUID, content3UUID, content4UUID, JohnSmithFSUUID, JohnSmithSmartlogicUUID, JohnSmithTMEUUID, JohnSmithOtherTMEUUID)
+
+	writeJohnSmithContent(assert)
+
+	contentByConceptDriver := &ConceptService{conn: db}
+	resolved, err := contentByConceptDriver.ResolveConcepts([]string{JohnSmithFSUUID, JohnSmithSmartlogicUUID, MSJConceptUUID})
+	assert.NoError(err, "Unexpected error resolving concepts")
+	assertListContainsAll(assert, resolved,
+		ResolvedConcept{UUID: JohnSmithFSUUID, CanonicalUUID: JohnSmithSmartlogicUUID},
+		ResolvedConcept{UUID: JohnSmithSmartlogicUUID, CanonicalUUID: JohnSmithSmartlogicUUID},
+	)
+}
+
+func TestConceptsAreFoundByAuthorityIdentifier(t *testing.T) {
+	assert := assert.New(t)
+
+	defer cleanDB(t, contentUUID, content2UUID, content3UUID, content4UUID, JohnSmithFSUUID, JohnSmithSmartlogicUUID, JohnSmithTMEUUID, JohnSmithOtherTMEUUID, FakebookConceptUUID)
+
+	writeJohnSmithContent(assert)
+	writeConcept(assert, db, "./fixtures/Organisation-Fakebook-eac853f5-3859-4c08-8540-55e043719400.json")
+
+	contentByConceptDriver := &ConceptService{conn: db}
+
+	tests := []struct {
+		authority       string
+		identifierValue string
+		expectedUUIDs   []string
+	}{
+		{"FACTSET", "0ABCD-E", []string{JohnSmithSmartlogicUUID}},
+		{"TME", "MwNGJhM2Vi-T04=", []string{JohnSmithSmartlogicUUID}},
+		{"LEI", "BQ4BKCS1HXDV9TTTTTTTT", []string{FakebookConceptUUID}},
+		{"FACTSET", "MwNGJhM2Vi-T04=", nil},
+	}
+
+	for _, test := range tests {
+		conceptUUIDs, err := contentByConceptDriver.FindConceptsByIdentifier(test.authority, test.identifierValue)
+		assert.NoError(err, "Unexpected error for %s identifier %s", test.authority, test.identifierValue)
+		assert.Equal(test.expectedUUIDs, conceptUUIDs, "Didn't find the right concepts for %s identifier %s", test.authority, test.identifierValue)
+	}
+}
+
+func TestConceptsAreFoundByLabel(t *testing.T) {
+	assert := assert.New(t)
+
+	defer cleanDB(t, contentUUID, content2UUID, content3UUID, content4UUID, JohnSmithFSUUID, JohnSmithSmartlogicUUID, JohnSmithTMEUUID, JohnSmithOtherTMEUUID, MSJConceptUUID)
+
+	writeJohnSmithContent(assert)
+	writeConcept(assert, db, "./fixtures/Organisation-MSJ-5d1510f8-2779-4b74-adab-0a5eb138fca6.json")
+
+	contentByConceptDriver := &ConceptService{conn: db}
+
+	tests := []struct {
+		label         string
+		conceptType   string
+		expectedUUIDs []string
+	}{
+		{"John Smith", "", []string{JohnSmithSmartlogicUUID}},
+		{"Smithy", "Person", []string{JohnSmithSmartlogicUUID}},
+		{"smithy", "Person", nil},
+		// aliases are only matched for a given concept type
+		{"Smithy", "", nil},
+		{"Johnny Boy", "Person", []string{JohnSmithSmartlogicUUID}},
+		{"MSJ", "Organisation", []string{MSJConceptUUID}},
+		{"MSJ", "Person", nil},
+		{"Nobody", "", nil},
+	}
+
+	for _, test := range tests {
+		candidates, err := contentByConceptDriver.FindConceptsByLabel(test.label, test.conceptType)
+		assert.NoError(err, "Unexpected error for label %s", test.label)
+		var conceptUUIDs []string
+		for _, candidate := range candidates {
+			conceptUUIDs = append(conceptUUIDs, strings.TrimPrefix(candidate.ID, "http://api.ft.com/things/"))
+		}
+		assert.Equal(test.expectedUUIDs, conceptUUIDs, "Didn't find the right concepts for label %s", test.label)
+	}
+}
+
 func TestConceptService_Check(t *testing.T) {
 	assert := assert.New(t)
 	contentByConceptDriver := &ConceptService{conn: db}
@@ -288,6 +912,20 @@ func TestConceptService_Check(t *testing.T) {
 	assert.NoError(err, "Test should always pass when connected to db")
 }
 
+func writeJohnSmithContent(assert *assert.Assertions) {
+	writeContent(assert, db, contentUUID)
+	writeContent(assert, db, content2UUID)
+	writeContent(assert, db, content3UUID)
+	writeContent(assert, db, content4UUID)
+
+	writeAnnotations(assert, db, contentUUID, "v1", "./fixtures/Annotations-JohnSmith1-v1.json")
+	writeAnnotations(assert, db, content2UUID, "v1", "./fixtures/Annotations-JohnSmith2-v1.json")
+	writeAnnotations(assert, db, content3UUID, "v2", "./fixtures/Annotations-JohnSmith3-v2.json")
+	writeAnnotations(assert, db, content4UUID, "v2", "./fixtures/Annotations-JohnSmith4-v2.json")
+
+	writeConcept(assert, db, "./fixtures/Person-JohnSmith-f25b0f71-4cf9-4e3a-8510-14e86d922bfe.json")
+}
+
 func writeContent(assert *assert.Assertions, db neoutils.NeoConnection, contentUUID string) {
 	contentRW := cnt.NewCypherContentService(db)
 	assert.NoError(contentRW.Initialise())
@@ -350,6 +988,17 @@ func assertListContainsAll(assert *assert.Assertions, list interface{}, items ..
 	}
 }
 
+func getJohnSmithContent() []interface{} {
+	var expected []interface{}
+	for _, uuid := range []string{contentUUID, content2UUID, content3UUID, content4UUID} {
+		expected = append(expected, Content{
+			ID:     "http://www.ft.com/things/" + uuid,
+			APIURL: "http://api.ft.com/content/" + uuid,
+		})
+	}
+	return expected
+}
+
 func getExpectedContent() Content {
 	return Content{
 		ID:     "http://www.ft.com/things/" + contentUUID,
@@ -2,8 +2,8 @@ package main
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"net/url"
 	"regexp"
@@ -20,83 +20,336 @@ const (
 	defaultPage    = 1
 	defaultLimit   = 50
 	thingURIPrefix = "http://api.ft.com/things/"
-	dateTimeLayout = "2006-01-02"
+	dateLayout     = "2006-01-02"
+
+	exclusiveDateBounds = "exclusive"
+	inclusiveDateBounds = "inclusive"
+
+	includeAnnotation = "annotation"
+
+	defaultNarrowerDepth = 1
+
+	operatorAnd = "and"
+	operatorOr  = "or"
+
+	relativeDateNow         = "now"
+	resolvedDateRangeHeader = "X-Resolved-Date-Range"
+	nextCursorHeader        = "X-Next-Cursor"
+	concordanceUsedHeader   = "X-Concordance-Used"
+	canonicalConceptHeader  = "X-Canonical-Concept"
+	// allowEmptyResultsHeader opts in to a 200 with an empty list when a known concept has no matching content
+	allowEmptyResultsHeader = "X-Allow-Empty-Results"
+
+	// labelPrefix marks isAnnotatedBy values that look the concept up by its prefLabel or an alias
+	labelPrefix = "label:"
 )
 
-var UUIDRegex = regexp.MustCompile(`([0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12})$`)
+// relativeDateRegex matches date offsets from the current time such as -24h or -7d
+var relativeDateRegex = regexp.MustCompile(`^([+-])(\d+)([mhdw])$`)
+
+var relativeDateUnits = map[string]time.Duration{
+	"m": time.Minute,
+	"h": time.Hour,
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+}
 
 type dbContentForConceptGetter interface {
-	GetContentForConcept(conceptUUID string, params content.RequestParams) ([]content.Content, error)
+	GetContentForConcepts(conceptUUIDs []string, params content.RequestParams) (content.ContentPage, error)
+	ResolveConcepts(conceptUUIDs []string) ([]content.ResolvedConcept, error)
+	FindConceptsByIdentifier(authority, identifierValue string) ([]string, error)
+	FindConceptsByLabel(label, conceptType string) ([]content.ConceptCandidate, error)
+}
+
+// conceptCandidates is returned along with a 300 status when a label matches several concepts
+type conceptCandidates struct {
+	Message    string                     `json:"message"`
+	Candidates []content.ConceptCandidate `json:"candidates"`
 }
 
 type Handler struct {
 	ContentService     dbContentForConceptGetter
 	CacheControlHeader string
 	Log                *logger.UPPLogger
+	// Now is the clock relative dates are resolved against, defaults to time.Now
+	Now func() time.Time
+	// MaxLimit is the highest accepted value for limit, zero means no maximum
+	MaxLimit int
+	// MaxPaginationDepth is the number of items beyond which page can't be used, zero means no maximum
+	MaxPaginationDepth int
+	// MaxNarrowerDepth is the highest accepted value for narrowerDepth, zero means no maximum
+	MaxNarrowerDepth int
+	// RedirectToCanonical answers requests for concepts that aren't canonical with a redirect to the canonical ones
+	RedirectToCanonical bool
+	// RelevanceHalfLife is used by the recency-relevance sort, zero means content.DefaultRelevanceHalfLife
+	RelevanceHalfLife time.Duration
 }
 
 func (h *Handler) GetContentByConcept(w http.ResponseWriter, r *http.Request) {
 	transID := transactionidutils.GetTransactionIDFromRequest(r)
 
 	logEntry := h.Log.WithTransactionID(transID)
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Header().Set(transactionidutils.TransactionIDHeader, transID)
+	// the same URL gets an envelope or not, and a 404 or an empty list, depending on these request headers
+	w.Header().Set("Vary", "Accept, "+allowEmptyResultsHeader)
 
 	m, err := url.ParseQuery(r.URL.RawQuery)
 	if err != nil {
 		logEntry.WithError(err).Error("Could not parse request url")
+		writeProblem(w, http.StatusBadRequest, problemInvalidParameter, "The request query could not be parsed.")
 		return
 	}
-	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
-	w.Header().Set(transactionidutils.TransactionIDHeader, transID)
 	logEntry.Debugf("Request url is %s", r.URL.RawQuery)
 
-	conceptURI := m.Get("isAnnotatedBy")
-	if conceptURI == "" {
-		writeJSONMessage(w, http.StatusBadRequest, "Missing or empty query parameter isAnnotatedBy. Expecting valid absolute concept URI.")
+	conceptURIs := m["isAnnotatedBy"]
+	authority, identifierValue := m.Get("authority"), m.Get("identifierValue")
+	identifierUsed := authority != "" || identifierValue != ""
+	if identifierUsed {
+		if len(conceptURIs) > 0 {
+			writeProblem(w, http.StatusBadRequest, problemInvalidParameter, "isAnnotatedBy and authority cannot be provided together.", "isAnnotatedBy", "authority")
+			return
+		}
+		if authority == "" || identifierValue == "" {
+			writeProblem(w, http.StatusBadRequest, problemInvalidParameter, "authority and identifierValue must be provided together.", "authority", "identifierValue")
+			return
+		}
+	} else if len(conceptURIs) == 0 {
+		writeProblem(w, http.StatusBadRequest, problemInvalidParameter, "Missing or empty query parameter isAnnotatedBy. Expecting valid absolute concept URI.", "isAnnotatedBy")
+		return
+	}
+
+	conceptType := m.Get("conceptType")
+	if conceptType != "" && !content.IsValidConceptType(conceptType) {
+		writeProblem(w, http.StatusBadRequest, problemInvalidParameter, fmt.Sprintf("provided value for conceptType, %s, is not a supported concept type.", conceptType), "conceptType")
+		return
+	}
+
+	labelUsed := false
+	for _, conceptURI := range conceptURIs {
+		if conceptURI == "" {
+			writeProblem(w, http.StatusBadRequest, problemInvalidParameter, "Missing or empty query parameter isAnnotatedBy. Expecting valid absolute concept URI.", "isAnnotatedBy")
+			return
+		}
+		if strings.HasPrefix(conceptURI, labelPrefix) {
+			labelUsed = true
+			if conceptURI == labelPrefix {
+				writeProblem(w, http.StatusBadRequest, problemInvalidParameter, "Missing label in isAnnotatedBy value "+labelPrefix, "isAnnotatedBy")
+				return
+			}
+		} else if _, err = parseConceptID(conceptURI); err != nil {
+			writeProblem(w, http.StatusBadRequest, problemInvalidParameter, err.Error(), "isAnnotatedBy")
+			return
+		}
+	}
+	if conceptType != "" && !labelUsed {
+		writeProblem(w, http.StatusBadRequest, problemInvalidParameter, "conceptType can only be provided together with a label: value for isAnnotatedBy.", "conceptType")
 		return
 	}
 
-	conceptUUID := strings.TrimPrefix(conceptURI, thingURIPrefix)
-	if !UUIDRegex.MatchString(conceptUUID) {
-		writeJSONMessage(w, http.StatusBadRequest, fmt.Sprintf("%s extracted from request URL was not valid uuid", conceptUUID))
+	requestParams, err := h.extractRequestParams(m, logEntry)
+	if err != nil {
+		writeBadRequest(w, err)
 		return
 	}
-	logEntry = logEntry.WithUUID(conceptUUID)
 
-	requestParams, err := extractRequestParams(m, logEntry)
+	useEnvelope, err := envelopeRequested(r, m)
 	if err != nil {
-		writeJSONMessage(w, http.StatusBadRequest, err.Error())
+		writeBadRequest(w, err)
 		return
 	}
+	requestParams.IncludeTotal = useEnvelope
 
-	contentList, err := h.ContentService.GetContentForConcept(conceptUUID, requestParams)
+	allowEmptyResults, err := emptyResultsAllowed(r)
 	if err != nil {
+		writeBadRequest(w, err)
+		return
+	}
 
-		if err == content.ErrContentNotFound {
-			msg := fmt.Sprintf("No content found for concept with uuid %s", conceptUUID)
-			logEntry.Debugf(msg)
-			writeJSONMessage(w, http.StatusNotFound, msg)
+	// concepts given by identifier or label are only looked up once the whole request is known to be valid
+	if identifierUsed {
+		conceptUUID, ok := h.findConceptByIdentifier(w, authority, identifierValue, logEntry)
+		if !ok {
 			return
 		}
+		conceptURIs = []string{conceptUUID}
+	}
+	var conceptUUIDs []string
+	seen := map[string]bool{}
+	for _, conceptURI := range conceptURIs {
+		var conceptUUID string
+		if strings.HasPrefix(conceptURI, labelPrefix) {
+			var ok bool
+			if conceptUUID, ok = h.findConceptByLabel(w, strings.TrimPrefix(conceptURI, labelPrefix), conceptType, logEntry); !ok {
+				return
+			}
+		} else {
+			// the URIs were validated above
+			conceptUUID, _ = parseConceptID(conceptURI)
+		}
+		if !seen[conceptUUID] {
+			seen[conceptUUID] = true
+			conceptUUIDs = append(conceptUUIDs, conceptUUID)
+		}
+	}
+	concepts := describeConcepts(conceptUUIDs)
+	logEntry = logEntry.WithUUID(strings.Join(conceptUUIDs, ","))
+
+	if dateRange := resolvedDateRange(requestParams); dateRange != "" {
+		w.Header().Set(resolvedDateRangeHeader, dateRange)
+	}
+
+	if h.RedirectToCanonical {
+		resolved, err := h.ContentService.ResolveConcepts(conceptUUIDs)
+		if err != nil {
+			msg := fmt.Sprintf("Backend error resolving %s", concepts)
+			logEntry.WithError(err).Error(msg)
+			writeProblem(w, http.StatusServiceUnavailable, problemBackendUnavailable, msg)
+			return
+		}
+		canonicalUUIDs := canonicalConcepts(conceptUUIDs, resolved)
+		if strings.Join(canonicalUUIDs, ",") != strings.Join(conceptUUIDs, ",") {
+			// the canonical concepts are given by uuid, so the type that labels were looked up with no longer applies
+			location := pageURL(r, func(q url.Values) {
+				q.Del("conceptType")
+				q.Del("isAnnotatedBy")
+				for _, canonicalUUID := range canonicalUUIDs {
+					q.Add("isAnnotatedBy", thingURIPrefix+canonicalUUID)
+				}
+			})
+			logEntry.Debugf("Redirecting to the canonical concepts %s", location)
+			w.Header().Set("Cache-Control", h.CacheControlHeader)
+			w.Header().Set("Location", location)
+			w.WriteHeader(http.StatusMovedPermanently)
+			return
+		}
+	}
 
-		msg := fmt.Sprintf("Backend error returning content for concept with uuid %s", conceptUUID)
+	contentPage, err := h.ContentService.GetContentForConcepts(conceptUUIDs, requestParams)
+	switch {
+	case err == content.ErrConceptNotFound:
+		msg := fmt.Sprintf("Unknown %s", describeConcepts(unknownConcepts(conceptUUIDs, contentPage.Concepts)))
+		logEntry.Debugf(msg)
+		writeProblem(w, http.StatusNotFound, problemConceptNotFound, msg)
+		return
+	case err == content.ErrContentNotFound && allowEmptyResults:
+		contentPage.Content = []content.Content{}
+	case err == content.ErrContentNotFound:
+		msg := fmt.Sprintf("No content found for %s", concepts)
+		logEntry.Debugf(msg)
+		writeProblem(w, http.StatusNotFound, problemContentNotFound, msg)
+		return
+	case err != nil:
+		msg := fmt.Sprintf("Backend error returning content for %s", concepts)
 		logEntry.WithError(err).Error(msg)
-		writeJSONMessage(w, http.StatusServiceUnavailable, msg)
+		writeProblem(w, http.StatusServiceUnavailable, problemBackendUnavailable, msg)
 		return
 	}
 
+	links := buildPaginationLinks(r, requestParams, contentPage, h.MaxPaginationDepth)
+
 	w.Header().Set("Cache-Control", h.CacheControlHeader)
+	w.Header().Set(concordanceUsedHeader, strconv.FormatBool(contentPage.Concorded()))
+	w.Header().Set(canonicalConceptHeader, strings.Join(canonicalConcepts(conceptUUIDs, contentPage.Concepts), ","))
+	if contentPage.NextCursor != nil {
+		w.Header().Set(nextCursorHeader, contentPage.NextCursor.Encode())
+	}
+	if link := linkHeader(links); link != "" {
+		w.Header().Set("Link", link)
+	}
+
+	var body interface{} = contentPage.Content
+	if useEnvelope {
+		w.Header().Set("Content-Type", contentListMediaType+"; charset=UTF-8")
+		envelope := contentListEnvelope{
+			Items: contentPage.Content,
+			Total: contentPage.Total,
+			Limit: requestParams.ContentLimit,
+			Links: links,
+		}
+		if requestParams.Cursor == nil {
+			envelope.Page = requestParams.Page
+		}
+		body = envelope
+	}
 	w.WriteHeader(http.StatusOK)
 
-	if err = json.NewEncoder(w).Encode(contentList); err != nil {
-		msg := fmt.Sprintf("Error parsing returned content list for concept with uuid %s", conceptUUID)
+	if err = json.NewEncoder(w).Encode(body); err != nil {
+		msg := fmt.Sprintf("Error parsing returned content list for %s", concepts)
 		logEntry.WithError(err).Error(msg)
-		writeJSONMessage(w, http.StatusInternalServerError, msg)
+		writeProblem(w, http.StatusInternalServerError, problemInternalError, msg)
 		return
 	}
 }
 
-func extractRequestParams(val url.Values, log *logger.LogEntry) (content.RequestParams, error) {
+// findConceptByIdentifier returns the uuid of the only canonical concept holding the authority identifier.
+// When no concept or several concepts match, the response is written and false is returned.
+func (h *Handler) findConceptByIdentifier(w http.ResponseWriter, authority, identifierValue string, logEntry *logger.LogEntry) (string, bool) {
+	matches, err := h.ContentService.FindConceptsByIdentifier(authority, identifierValue)
+	if err != nil {
+		msg := fmt.Sprintf("Backend error finding concept with %s identifier %s", authority, identifierValue)
+		logEntry.WithError(err).Error(msg)
+		writeProblem(w, http.StatusServiceUnavailable, problemBackendUnavailable, msg)
+		return "", false
+	}
+	switch len(matches) {
+	case 0:
+		msg := fmt.Sprintf("No concept found with %s identifier %s", authority, identifierValue)
+		logEntry.Debugf(msg)
+		writeProblem(w, http.StatusNotFound, problemConceptNotFound, msg)
+		return "", false
+	case 1:
+		return matches[0], true
+	default:
+		msg := fmt.Sprintf("%s identifier %s matches several concepts: %s", authority, identifierValue, strings.Join(matches, ", "))
+		logEntry.Debugf(msg)
+		writeProblem(w, http.StatusConflict, problemAmbiguousConcept, msg)
+		return "", false
+	}
+}
+
+// findConceptByLabel returns the uuid of the only canonical concept matching the label. When no concept
+// or several concepts match, the response is written and false is returned.
+func (h *Handler) findConceptByLabel(w http.ResponseWriter, label, conceptType string, logEntry *logger.LogEntry) (string, bool) {
+	candidates, err := h.ContentService.FindConceptsByLabel(label, conceptType)
+	if err != nil {
+		msg := fmt.Sprintf("Backend error finding concept with label %s", label)
+		logEntry.WithError(err).Error(msg)
+		writeProblem(w, http.StatusServiceUnavailable, problemBackendUnavailable, msg)
+		return "", false
+	}
+	switch len(candidates) {
+	case 0:
+		msg := fmt.Sprintf("No concept found with label %s", label)
+		if conceptType == "" {
+			msg += ", aliases are only matched when conceptType is given"
+		}
+		logEntry.Debugf(msg)
+		writeProblem(w, http.StatusNotFound, problemConceptNotFound, msg)
+		return "", false
+	case 1:
+		return strings.TrimPrefix(candidates[0].ID, thingURIPrefix), true
+	default:
+		msg := fmt.Sprintf("Label %s matches several concepts, use conceptType or one of the candidate ids instead.", label)
+		logEntry.Debugf(msg)
+		w.WriteHeader(http.StatusMultipleChoices)
+		if err := json.NewEncoder(w).Encode(conceptCandidates{Message: msg, Candidates: candidates}); err != nil {
+			logEntry.WithError(err).Error("Error writing concept candidates")
+		}
+		return "", false
+	}
+}
+
+func (h *Handler) now() time.Time {
+	if h.Now == nil {
+		return time.Now()
+	}
+	return h.Now()
+}
+
+func (h *Handler) extractRequestParams(val url.Values, log *logger.LogEntry) (content.RequestParams, error) {
 	var (
+		now           = h.now()
 		page          = defaultPage
 		contentLimit  = defaultLimit
 		fromDateEpoch = int64(0)
@@ -110,40 +363,89 @@ func extractRequestParams(val url.Values, log *logger.LogEntry) (content.Request
 		if err != nil {
 			msg := fmt.Sprintf("provided value for page, %s, could not be parsed.", pageParam)
 			log.WithError(err).Error(msg)
-			return content.RequestParams{}, errors.New(msg)
+			return content.RequestParams{}, newParamError(msg, "page")
 		}
 
 		if page < defaultPage {
 			msg := fmt.Sprintf("provided value for page should be greater than: %v", defaultPage)
 			log.Debugf(msg)
-			return content.RequestParams{}, errors.New(msg)
+			return content.RequestParams{}, newParamError(msg, "page")
 		}
 	}
 
+	sort := val.Get("sort")
+	if sort == "" {
+		sort = content.DefaultSort
+	} else if !content.IsValidSort(sort) {
+		msg := fmt.Sprintf("provided value for sort, %s, is not a supported sort order.", sort)
+		log.Debugf(msg)
+		return content.RequestParams{}, newParamError(msg, "sort")
+	}
+
+	var cursor *content.Cursor
+	cursorParam := val.Get("cursor")
+	if cursorParam != "" {
+		if pageParam != "" {
+			msg := "page and cursor cannot be provided together."
+			log.Debugf(msg)
+			return content.RequestParams{}, newParamError(msg, "page", "cursor")
+		}
+		c, err := content.DecodeCursor(cursorParam)
+		if err != nil {
+			msg := fmt.Sprintf("provided value for cursor, %s, is not a valid cursor.", cursorParam)
+			log.WithError(err).Debug(msg)
+			return content.RequestParams{}, newParamError(msg, "cursor")
+		}
+		if c.Sort != sort {
+			msg := fmt.Sprintf("provided value for cursor, %s, was returned for a different sort order.", cursorParam)
+			log.Debugf(msg)
+			return content.RequestParams{}, newParamError(msg, "cursor")
+		}
+		cursor = &c
+	}
+
 	limitParam := val.Get("limit")
 
 	if limitParam == "" {
 		log.Debugf("No contentLimit provided. Using default: %d", defaultLimit)
 	} else {
-		limit, err := strconv.Atoi(limitParam)
+		contentLimit, err = strconv.Atoi(limitParam)
 		if err != nil {
-			log.Debugf("provided value for contentLimit, %s, could not be parsed. Using default: %d", limitParam, defaultLimit)
-		} else {
-			contentLimit = limit
+			msg := fmt.Sprintf("provided value for limit, %s, could not be parsed.", limitParam)
+			log.WithError(err).Debug(msg)
+			return content.RequestParams{}, newParamError(msg, "limit")
+		}
+
+		if contentLimit < 1 {
+			msg := "provided value for limit should be greater than: 0"
+			log.Debugf(msg)
+			return content.RequestParams{}, newParamError(msg, "limit")
+		}
+
+		if h.MaxLimit > 0 && contentLimit > h.MaxLimit {
+			msg := fmt.Sprintf("provided value for limit should not be greater than: %d", h.MaxLimit)
+			log.Debugf(msg)
+			return content.RequestParams{}, newParamError(msg, "limit")
 		}
 	}
 
+	if cursor == nil && h.MaxPaginationDepth > 0 && page*contentLimit > h.MaxPaginationDepth {
+		msg := fmt.Sprintf("provided values for page and limit go beyond the maximum pagination depth of %d items, use cursor to paginate further.", h.MaxPaginationDepth)
+		log.Debugf(msg)
+		return content.RequestParams{}, newParamError(msg, "page", "limit")
+	}
+
 	fromDateParam := val.Get("fromDate")
 	toDateParam := val.Get("toDate")
 
 	if fromDateParam == "" {
 		log.Debug("no fromDate url param supplied")
 	} else {
-		fromDateTime, err := time.Parse(dateTimeLayout, fromDateParam)
+		fromDateTime, err := parseDate(fromDateParam, now)
 		if err != nil {
-			msg := fmt.Sprintf("From date value %s could not be parsed", fromDateParam)
+			msg := fmt.Sprintf("From date value %s could not be parsed, expecting a date in YYYY-MM-DD format, an RFC3339 timestamp or a relative date such as now or -24h", fromDateParam)
 			log.WithError(err).Error(msg)
-			return content.RequestParams{}, errors.New(msg)
+			return content.RequestParams{}, newParamError(msg, "fromDate")
 		}
 		fromDateEpoch = fromDateTime.Unix()
 	}
@@ -151,24 +453,314 @@ func extractRequestParams(val url.Values, log *logger.LogEntry) (content.Request
 	if toDateParam == "" {
 		log.Debug("no toDate url param supplied")
 	} else {
-		toDateTime, err := time.Parse(dateTimeLayout, toDateParam)
+		toDateTime, err := parseDate(toDateParam, now)
 		if err != nil {
-			msg := fmt.Sprintf("To date value %s could not be parsed", toDateParam)
+			msg := fmt.Sprintf("To date value %s could not be parsed, expecting a date in YYYY-MM-DD format, an RFC3339 timestamp or a relative date such as now or -24h", toDateParam)
 			log.WithError(err).Error(msg)
-			return content.RequestParams{}, errors.New(msg)
+			return content.RequestParams{}, newParamError(msg, "toDate")
 		}
 		toDateEpoch = toDateTime.Unix()
 	}
 
+	if fromDateEpoch > 0 && toDateEpoch > 0 && fromDateEpoch > toDateEpoch {
+		msg := fmt.Sprintf("From date value %s is after to date value %s", fromDateParam, toDateParam)
+		log.Debugf(msg)
+		return content.RequestParams{}, newParamError(msg, "fromDate", "toDate")
+	}
+
+	inclusiveBounds := false
+	dateBoundsParam := val.Get("dateBounds")
+	switch dateBoundsParam {
+	case "", exclusiveDateBounds:
+	case inclusiveDateBounds:
+		inclusiveBounds = true
+	default:
+		msg := fmt.Sprintf("provided value for dateBounds, %s, is not supported. Expecting %s or %s.", dateBoundsParam, exclusiveDateBounds, inclusiveDateBounds)
+		log.Debugf(msg)
+		return content.RequestParams{}, newParamError(msg, "dateBounds")
+	}
+
+	minRelevance, err := parseScore(val, "minRelevance")
+	if err != nil {
+		log.Debug(err.Error())
+		return content.RequestParams{}, err
+	}
+	minConfidence, err := parseScore(val, "minConfidence")
+	if err != nil {
+		log.Debug(err.Error())
+		return content.RequestParams{}, err
+	}
+
+	matchAnyConcept := false
+	operatorParam := val.Get("operator")
+	switch operatorParam {
+	case "", operatorAnd:
+	case operatorOr:
+		matchAnyConcept = true
+	default:
+		msg := fmt.Sprintf("provided value for operator, %s, is not supported. Expecting %s or %s.", operatorParam, operatorAnd, operatorOr)
+		log.Debugf(msg)
+		return content.RequestParams{}, newParamError(msg, "operator")
+	}
+
+	var excludedConceptUUIDs []string
+	for _, conceptURI := range val["notAnnotatedBy"] {
+		conceptUUID, err := parseConceptID(conceptURI)
+		if err != nil {
+			msg := fmt.Sprintf("provided value for notAnnotatedBy, %s, is not a valid concept URI or uuid.", conceptURI)
+			log.WithError(err).Debug(msg)
+			return content.RequestParams{}, newParamError(msg, "notAnnotatedBy")
+		}
+		excludedConceptUUIDs = append(excludedConceptUUIDs, conceptUUID)
+	}
+
+	narrowerDepth, err := h.extractNarrowerDepth(val)
+	if err != nil {
+		log.Debug(err.Error())
+		return content.RequestParams{}, err
+	}
+
+	includeSubsidiaries, err := parseBoolParam(val, "includeSubsidiaries")
+	if err != nil {
+		log.Debug(err.Error())
+		return content.RequestParams{}, err
+	}
+	includeMemberships, err := parseBoolParam(val, "includeMemberships")
+	if err != nil {
+		log.Debug(err.Error())
+		return content.RequestParams{}, err
+	}
+
+	predicates := val["predicate"]
+	for _, predicate := range predicates {
+		if !content.IsValidPredicate(predicate) {
+			msg := fmt.Sprintf("provided value for predicate, %s, is not a supported annotation predicate.", predicate)
+			log.Debugf(msg)
+			return content.RequestParams{}, newParamError(msg, "predicate")
+		}
+	}
+
+	contentTypes := val["type"]
+	for _, contentType := range contentTypes {
+		if !content.IsValidContentType(contentType) {
+			msg := fmt.Sprintf("provided value for type, %s, is not a supported content type.", contentType)
+			log.Debugf(msg)
+			return content.RequestParams{}, newParamError(msg, "type")
+		}
+	}
+
+	excludedContentTypes := val["excludeType"]
+	for _, contentType := range excludedContentTypes {
+		if !content.IsValidContentType(contentType) {
+			msg := fmt.Sprintf("provided value for excludeType, %s, is not a supported content type.", contentType)
+			log.Debugf(msg)
+			return content.RequestParams{}, newParamError(msg, "excludeType")
+		}
+	}
+
+	var fields []string
+	for _, fieldsParam := range val["fields"] {
+		for _, field := range strings.Split(fieldsParam, ",") {
+			field = strings.TrimSpace(field)
+			if !content.IsValidField(field) {
+				msg := fmt.Sprintf("provided value for fields, %s, is not a supported content field.", field)
+				log.Debugf(msg)
+				return content.RequestParams{}, newParamError(msg, "fields")
+			}
+			fields = append(fields, field)
+		}
+	}
+
+	includeAnnotations := false
+	for _, includeParam := range val["include"] {
+		for _, include := range strings.Split(includeParam, ",") {
+			switch strings.TrimSpace(include) {
+			case includeAnnotation:
+				includeAnnotations = true
+			default:
+				msg := fmt.Sprintf("provided value for include, %s, is not supported. Expecting %s.", include, includeAnnotation)
+				log.Debugf(msg)
+				return content.RequestParams{}, newParamError(msg, "include")
+			}
+		}
+	}
+
 	return content.RequestParams{
-		Page:          page,
-		ContentLimit:  contentLimit,
-		FromDateEpoch: fromDateEpoch,
-		ToDateEpoch:   toDateEpoch,
+		Page:                 page,
+		ContentLimit:         contentLimit,
+		FromDateEpoch:        fromDateEpoch,
+		ToDateEpoch:          toDateEpoch,
+		InclusiveDateBounds:  inclusiveBounds,
+		MinRelevance:         minRelevance,
+		MinConfidence:        minConfidence,
+		Predicates:           predicates,
+		MatchAnyConcept:      matchAnyConcept,
+		ExcludedConceptUUIDs: excludedConceptUUIDs,
+		NarrowerDepth:        narrowerDepth,
+		IncludeSubsidiaries:  includeSubsidiaries,
+		IncludeMemberships:   includeMemberships,
+		ContentTypes:         contentTypes,
+		ExcludedContentTypes: excludedContentTypes,
+		Sort:                 sort,
+		RelevanceHalfLife:    h.RelevanceHalfLife,
+		Cursor:               cursor,
+		Fields:               fields,
+		IncludeAnnotations:   includeAnnotations,
 	}, nil
 }
 
-func writeJSONMessage(w http.ResponseWriter, status int, msg string) {
-	w.WriteHeader(status)
-	_, _ = w.Write([]byte(`{"message": "` + msg + `"}`))
+// parseDate accepts a plain date, an RFC3339 timestamp or a date relative to now,
+// given either as now or as an offset in minutes, hours, days or weeks e.g. -7d
+func parseDate(value string, now time.Time) (time.Time, error) {
+	if value == relativeDateNow {
+		return now, nil
+	}
+	if match := relativeDateRegex.FindStringSubmatch(value); match != nil {
+		amount, err := strconv.Atoi(match[2])
+		if err != nil {
+			return time.Time{}, err
+		}
+		unit := relativeDateUnits[match[3]]
+		// offsets that don't fit in a duration, about 292 years, would silently wrap around
+		if int64(amount) > int64(math.MaxInt64/unit) {
+			return time.Time{}, fmt.Errorf("relative date %s is out of range", value)
+		}
+		offset := time.Duration(amount) * unit
+		if match[1] == "-" {
+			offset = -offset
+		}
+		return now.Add(offset), nil
+	}
+	if t, err := time.Parse(dateLayout, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// extractNarrowerDepth returns how many levels of narrower concepts to include, zero unless includeNarrower is set
+func (h *Handler) extractNarrowerDepth(val url.Values) (int, error) {
+	depthParam := val.Get("narrowerDepth")
+	includeNarrower, err := parseBoolParam(val, "includeNarrower")
+	if err != nil {
+		return 0, err
+	}
+	if !includeNarrower {
+		if depthParam != "" {
+			return 0, newParamError("narrowerDepth can only be provided together with includeNarrower=true.", "narrowerDepth", "includeNarrower")
+		}
+		return 0, nil
+	}
+	if depthParam == "" {
+		return defaultNarrowerDepth, nil
+	}
+
+	depth, err := strconv.Atoi(depthParam)
+	if err != nil {
+		return 0, newParamError(fmt.Sprintf("provided value for narrowerDepth, %s, could not be parsed.", depthParam), "narrowerDepth")
+	}
+	if depth < 1 {
+		return 0, newParamError("provided value for narrowerDepth should be greater than: 0", "narrowerDepth")
+	}
+	if h.MaxNarrowerDepth > 0 && depth > h.MaxNarrowerDepth {
+		return 0, newParamError(fmt.Sprintf("provided value for narrowerDepth should not be greater than: %d", h.MaxNarrowerDepth), "narrowerDepth")
+	}
+	return depth, nil
+}
+
+// parseBoolParam reads an optional boolean query parameter, false when it is not provided
+func parseBoolParam(val url.Values, name string) (bool, error) {
+	param := val.Get(name)
+	if param == "" {
+		return false, nil
+	}
+	value, err := strconv.ParseBool(param)
+	if err != nil {
+		return false, newParamError(fmt.Sprintf("provided value for %s, %s, could not be parsed.", name, param), name)
+	}
+	return value, nil
+}
+
+// canonicalConcepts returns the canonical concept of each of the queried concepts, in the same order.
+// Concepts that weren't found are kept as they are.
+func canonicalConcepts(conceptUUIDs []string, resolved []content.ResolvedConcept) []string {
+	canonical := map[string]string{}
+	for _, concept := range resolved {
+		canonical[concept.UUID] = concept.Canonical()
+	}
+	var canonicalUUIDs []string
+	seen := map[string]bool{}
+	for _, conceptUUID := range conceptUUIDs {
+		canonicalUUID, ok := canonical[conceptUUID]
+		if !ok {
+			canonicalUUID = conceptUUID
+		}
+		if !seen[canonicalUUID] {
+			seen[canonicalUUID] = true
+			canonicalUUIDs = append(canonicalUUIDs, canonicalUUID)
+		}
+	}
+	return canonicalUUIDs
+}
+
+// unknownConcepts returns the queried concepts that couldn't be resolved
+func unknownConcepts(conceptUUIDs []string, resolved []content.ResolvedConcept) []string {
+	found := map[string]bool{}
+	for _, concept := range resolved {
+		found[concept.UUID] = true
+	}
+	var unknown []string
+	for _, conceptUUID := range conceptUUIDs {
+		if !found[conceptUUID] {
+			unknown = append(unknown, conceptUUID)
+		}
+	}
+	return unknown
+}
+
+// emptyResultsAllowed reports whether the client opted in to an empty list, rather than a 404,
+// when the concepts exist but have no matching content
+func emptyResultsAllowed(r *http.Request) (bool, error) {
+	headerValue := r.Header.Get(allowEmptyResultsHeader)
+	if headerValue == "" {
+		return false, nil
+	}
+	allowed, err := strconv.ParseBool(headerValue)
+	if err != nil {
+		return false, newParamError(fmt.Sprintf("provided value for %s header, %s, could not be parsed.", allowEmptyResultsHeader, headerValue), allowEmptyResultsHeader)
+	}
+	return allowed, nil
+}
+
+// describeConcepts names the queried concepts in messages
+func describeConcepts(conceptUUIDs []string) string {
+	if len(conceptUUIDs) == 1 {
+		return "concept with uuid " + conceptUUIDs[0]
+	}
+	return "concepts with uuids " + strings.Join(conceptUUIDs, ", ")
+}
+
+// parseScore reads an annotation score threshold, scores range from 0 to 1
+func parseScore(val url.Values, name string) (float64, error) {
+	scoreParam := val.Get(name)
+	if scoreParam == "" {
+		return 0, nil
+	}
+	score, err := strconv.ParseFloat(scoreParam, 64)
+	if err != nil || !(score >= 0 && score <= 1) {
+		return 0, newParamError(fmt.Sprintf("provided value for %s, %s, is not a score between 0 and 1.", name, scoreParam), name)
+	}
+	return score, nil
+}
+
+// resolvedDateRange describes the absolute date range applied to the request
+// so clients using relative dates can see which window was used
+func resolvedDateRange(params content.RequestParams) string {
+	var bounds []string
+	if params.FromDateEpoch > 0 {
+		bounds = append(bounds, "fromDate="+time.Unix(params.FromDateEpoch, 0).UTC().Format(time.RFC3339))
+	}
+	if params.ToDateEpoch > 0 {
+		bounds = append(bounds, "toDate="+time.Unix(params.ToDateEpoch, 0).UTC().Format(time.RFC3339))
+	}
+	return strings.Join(bounds, "; ")
 }
@@ -1,11 +1,14 @@
 package main
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/Financial-Times/go-logger/v2"
 	"github.com/Financial-Times/neo-model-utils-go/mapper"
@@ -16,6 +19,7 @@ import (
 const (
 	testConceptID    = "44129750-7616-11e8-b45a-da24cd01f044"
 	testContentUUID  = "e89db5e2-760d-11e8-b45a-da24cd01f044"
+	testContent2UUID = "0a3c3f86-7ac1-11e8-b45a-da24cd01f044"
 	anotherConceptID = "347e2eca-7860-11e8-b45a-da24cd01f044"
 )
 
@@ -32,9 +36,22 @@ func TestContentByConceptHandler_GetContentByConcept(t *testing.T) {
 		toDate             string
 		page               string
 		contentLimit       string
+		extraParams        string
+		requestHeaders     map[string]string
 		expectedStatusCode int
 		expectedBody       string
-		backendError       error
+		// expectedDetail and expectedInvalidParams are checked against the problem returned for errors
+		expectedDetail        string
+		expectedInvalidParams []string
+		expectedHeaders       map[string]string
+		backendError          error
+		unconcorded           bool
+		canonicalUUID         string
+		redirectToCanonical   bool
+		conceptMatches        []string
+		unknownConcept        bool
+		// expectedParams updates the default request params to the ones the service should receive
+		expectedParams func(p *content.RequestParams)
 	}{
 		{
 			testName:           "Success for request with full URL",
@@ -74,7 +91,7 @@ func TestContentByConceptHandler_GetContentByConcept(t *testing.T) {
 			conceptID:          "",
 			contentList:        []string{testContentUUID},
 			expectedStatusCode: 400,
-			expectedBody:       `{"message": "Missing or empty query parameter isAnnotatedBy. Expecting valid absolute concept URI."}`,
+			expectedDetail:     `Missing or empty query parameter isAnnotatedBy. Expecting valid absolute concept URI.`,
 		},
 		{
 			testName:           "Success: isAnnotatedBy has valid UUID",
@@ -88,14 +105,14 @@ func TestContentByConceptHandler_GetContentByConcept(t *testing.T) {
 			conceptID:          "NullURI",
 			contentList:        []string{testContentUUID},
 			expectedStatusCode: 400,
-			expectedBody:       `{"message": "Missing or empty query parameter isAnnotatedBy. Expecting valid absolute concept URI."}`,
+			expectedDetail:     `Missing or empty query parameter isAnnotatedBy. Expecting valid absolute concept URI.`,
 		},
 		{
 			testName:           "Bad Request: isAnnotatedBy URI has invalid UUID",
 			conceptID:          "123456",
 			contentList:        []string{testContentUUID},
 			expectedStatusCode: 400,
-			expectedBody:       `{"message": "123456 extracted from request URL was not valid uuid"}`,
+			expectedDetail:     `123456 extracted from request URL was not valid uuid`,
 		},
 		{
 			testName:           "Bad Request: query param 'page' is invalid",
@@ -103,7 +120,7 @@ func TestContentByConceptHandler_GetContentByConcept(t *testing.T) {
 			contentList:        []string{testContentUUID},
 			page:               "null",
 			expectedStatusCode: 400,
-			expectedBody:       `{"message": "provided value for page, null, could not be parsed."}`,
+			expectedDetail:     `provided value for page, null, could not be parsed.`,
 		},
 		{
 			testName:           "Bad Request: query param 'page' is less than defaultPage value",
@@ -117,8 +134,50 @@ func TestContentByConceptHandler_GetContentByConcept(t *testing.T) {
 			conceptID:          testConceptID,
 			contentList:        []string{testContentUUID},
 			contentLimit:       "null",
+			expectedStatusCode: 400,
+			expectedDetail:     `provided value for limit, null, could not be parsed.`,
+		},
+		{
+			testName:           "Bad Request: query param 'limit' is zero",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			contentLimit:       "0",
+			expectedStatusCode: 400,
+			expectedDetail:     `provided value for limit should be greater than: 0`,
+		},
+		{
+			testName:           "Bad Request: query param 'limit' is negative",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			contentLimit:       "-10",
+			expectedStatusCode: 400,
+			expectedDetail:     `provided value for limit should be greater than: 0`,
+		},
+		{
+			testName:           "Bad Request: query param 'limit' is greater than the maximum limit",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			contentLimit:       "1000000",
+			expectedStatusCode: 400,
+			expectedDetail:     `provided value for limit should not be greater than: 100`,
+		},
+		{
+			testName:           "Success for request with page and limit at the maximum pagination depth",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			page:               "10",
+			contentLimit:       "100",
 			expectedStatusCode: 200,
-			expectedBody:       "",
+		},
+		{
+			testName:              "Bad Request: query params 'page' and 'limit' go beyond the maximum pagination depth",
+			conceptID:             testConceptID,
+			contentList:           []string{testContentUUID},
+			page:                  "11",
+			contentLimit:          "100",
+			expectedStatusCode:    400,
+			expectedDetail:        `provided values for page and limit go beyond the maximum pagination depth of 1000 items, use cursor to paginate further.`,
+			expectedInvalidParams: []string{"page", "limit"},
 		},
 		{
 			testName:           "Bad Request: query param 'fromDate' is invalid",
@@ -126,7 +185,7 @@ func TestContentByConceptHandler_GetContentByConcept(t *testing.T) {
 			contentList:        []string{testContentUUID},
 			fromDate:           "null",
 			expectedStatusCode: 400,
-			expectedBody:       `{"message": "From date value null could not be parsed"}`,
+			expectedDetail:     `From date value null could not be parsed, expecting a date in YYYY-MM-DD format, an RFC3339 timestamp or a relative date such as now or -24h`,
 		},
 		{
 			testName:           "Bad Request: query param 'toDate' is invalid",
@@ -134,48 +193,763 @@ func TestContentByConceptHandler_GetContentByConcept(t *testing.T) {
 			contentList:        []string{testContentUUID},
 			toDate:             "null",
 			expectedStatusCode: 400,
-			expectedBody:       `{"message": "To date value null could not be parsed"}`,
+			expectedDetail:     `To date value null could not be parsed, expecting a date in YYYY-MM-DD format, an RFC3339 timestamp or a relative date such as now or -24h`,
+		},
+		{
+			testName:           "Success for request with only fromDate",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			fromDate:           "2018-01-01",
+			expectedStatusCode: 200,
+		},
+		{
+			testName:           "Success for request with only toDate",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			toDate:             "2018-06-20",
+			expectedStatusCode: 200,
+		},
+		{
+			testName:           "Bad Request: query param 'fromDate' is after 'toDate'",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			fromDate:           "2018-06-20",
+			toDate:             "2018-01-01",
+			expectedStatusCode: 400,
+			expectedDetail:     `From date value 2018-06-20 is after to date value 2018-01-01`,
+		},
+		{
+			testName:           "Success for request with RFC3339 timestamps",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			fromDate:           "2018-06-20T06:00:00Z",
+			toDate:             "2018-06-20T12:00:00%2B01:00",
+			expectedStatusCode: 200,
+		},
+		{
+			testName:           "Success for request with inclusive date bounds",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			fromDate:           "2018-01-01",
+			toDate:             "2018-06-20",
+			extraParams:        "dateBounds=inclusive",
+			expectedStatusCode: 200,
+			expectedParams: func(p *content.RequestParams) {
+				p.FromDateEpoch, p.ToDateEpoch, p.InclusiveDateBounds = 1514764800, 1529452800, true
+			},
+		},
+		{
+			testName:           "Bad Request: query param 'fromDate' has an invalid timestamp",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			fromDate:           "2018-06-20T25:00:00Z",
+			expectedStatusCode: 400,
+			expectedDetail:     `From date value 2018-06-20T25:00:00Z could not be parsed, expecting a date in YYYY-MM-DD format, an RFC3339 timestamp or a relative date such as now or -24h`,
+		},
+		{
+			testName:           "Bad Request: query param 'dateBounds' is not supported",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			extraParams:        "dateBounds=closed",
+			expectedStatusCode: 400,
+			expectedDetail:     `provided value for dateBounds, closed, is not supported. Expecting exclusive or inclusive.`,
+		},
+		{
+			testName:           "Success for request with relative dates",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			fromDate:           "-24h",
+			toDate:             "now",
+			expectedStatusCode: 200,
+			expectedHeaders:    map[string]string{"X-Resolved-Date-Range": "fromDate=2018-06-19T12:00:00Z; toDate=2018-06-20T12:00:00Z"},
+		},
+		{
+			testName:           "Success for request with relative fromDate in days",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			fromDate:           "-7d",
+			expectedStatusCode: 200,
+			expectedHeaders:    map[string]string{"X-Resolved-Date-Range": "fromDate=2018-06-13T12:00:00Z"},
+		},
+		{
+			testName:           "Bad Request: query param 'fromDate' has an unsupported relative unit",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			fromDate:           "-1y",
+			expectedStatusCode: 400,
+			expectedDetail:     `From date value -1y could not be parsed, expecting a date in YYYY-MM-DD format, an RFC3339 timestamp or a relative date such as now or -24h`,
+		},
+		{
+			testName:           "Bad Request: query param 'fromDate' is a relative date out of range",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			fromDate:           "-200000d",
+			expectedStatusCode: 400,
+			expectedDetail:     `From date value -200000d could not be parsed, expecting a date in YYYY-MM-DD format, an RFC3339 timestamp or a relative date such as now or -24h`,
+		},
+		{
+			testName:           "Success for request with a next page returns the next cursor",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID, testContent2UUID},
+			contentLimit:       "1",
+			expectedStatusCode: 200,
+			expectedHeaders:    map[string]string{"X-Next-Cursor": content.Cursor{Sort: "publishedDate", UUID: testContentUUID}.Encode()},
+		},
+		{
+			testName:           "Success for request on the last page has no next cursor",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID, testContent2UUID},
+			contentLimit:       "2",
+			expectedStatusCode: 200,
+			expectedHeaders:    map[string]string{"X-Next-Cursor": ""},
+		},
+		{
+			testName:           "Success for request with cursor",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			extraParams:        "cursor=" + content.Cursor{Sort: "publishedDate", Key: 1529496000, UUID: testContent2UUID}.Encode(),
+			expectedStatusCode: 200,
+		},
+		{
+			testName:           "Bad Request: query param 'cursor' is invalid",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			extraParams:        "cursor=not-a-cursor",
+			expectedStatusCode: 400,
+			expectedDetail:     `provided value for cursor, not-a-cursor, is not a valid cursor.`,
+		},
+		{
+			testName:           "Bad Request: query param 'cursor' was returned for another sort",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			extraParams:        "sort=annotatedDate&cursor=" + content.Cursor{Sort: "publishedDate", Key: 1529496000, UUID: testContent2UUID}.Encode(),
+			expectedStatusCode: 400,
+			expectedDetail:     `provided value for cursor, ` + content.Cursor{Sort: "publishedDate", Key: 1529496000, UUID: testContent2UUID}.Encode() + `, was returned for a different sort order.`,
+		},
+		{
+			testName:           "Success for request sorted by annotation date",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID, testContent2UUID},
+			contentLimit:       "1",
+			extraParams:        "sort=annotatedDate",
+			expectedStatusCode: 200,
+			expectedParams:     func(p *content.RequestParams) { p.ContentLimit, p.Sort = 1, "annotatedDate" },
+			expectedHeaders:    map[string]string{"X-Next-Cursor": content.Cursor{Sort: "annotatedDate", UUID: testContentUUID}.Encode()},
+		},
+		{
+			testName:           "Success for request sorted by recency and relevance",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID, testContent2UUID},
+			contentLimit:       "1",
+			extraParams:        "sort=recency-relevance",
+			expectedStatusCode: 200,
+			expectedHeaders:    map[string]string{"X-Next-Cursor": content.Cursor{Sort: "recency-relevance", UUID: testContentUUID}.Encode()},
+		},
+		{
+			testName:           "Success for request with minimum scores",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			extraParams:        "minRelevance=0.5&minConfidence=0.9",
+			expectedStatusCode: 200,
+			expectedParams:     func(p *content.RequestParams) { p.MinRelevance, p.MinConfidence = 0.5, 0.9 },
+		},
+		{
+			testName:           "Bad Request: query param 'minRelevance' is not a score",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			extraParams:        "minRelevance=high",
+			expectedStatusCode: 400,
+			expectedDetail:     `provided value for minRelevance, high, is not a score between 0 and 1.`,
+		},
+		{
+			testName:           "Bad Request: query param 'minConfidence' is out of range",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			extraParams:        "minConfidence=1.5",
+			expectedStatusCode: 400,
+			expectedDetail:     `provided value for minConfidence, 1.5, is not a score between 0 and 1.`,
+		},
+		{
+			testName:           "Bad Request: query param 'sort' is not supported",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			extraParams:        "sort=title",
+			expectedStatusCode: 400,
+			expectedDetail:     `provided value for sort, title, is not a supported sort order.`,
+		},
+		{
+			testName:              "Bad Request: query params 'cursor' and 'page' are both provided",
+			conceptID:             testConceptID,
+			contentList:           []string{testContentUUID},
+			page:                  "2",
+			extraParams:           "cursor=" + content.Cursor{Sort: "publishedDate", Key: 1529496000, UUID: testContent2UUID}.Encode(),
+			expectedStatusCode:    400,
+			expectedDetail:        `page and cursor cannot be provided together.`,
+			expectedInvalidParams: []string{"page", "cursor"},
+		},
+		{
+			testName:           "Success for request with envelope",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID, testContent2UUID},
+			page:               "2",
+			contentLimit:       "1",
+			extraParams:        "envelope=true",
+			expectedStatusCode: 200,
+			expectedBody:       `{"items":[{"id":"http://www.ft.com/things/e89db5e2-760d-11e8-b45a-da24cd01f044","apiUrl":"http://api.ft.com/content/e89db5e2-760d-11e8-b45a-da24cd01f044"}],"total":2,"page":2,"limit":1,"links":{"next":"/content?envelope=true\u0026isAnnotatedBy=http%3A%2F%2Fapi.ft.com%2Fthings%2F44129750-7616-11e8-b45a-da24cd01f044\u0026limit=1\u0026page=3","prev":"/content?envelope=true\u0026isAnnotatedBy=http%3A%2F%2Fapi.ft.com%2Fthings%2F44129750-7616-11e8-b45a-da24cd01f044\u0026limit=1\u0026page=1"}}` + "\n",
+			expectedHeaders: map[string]string{
+				"Content-Type": "application/vnd.ft.content-list+json; charset=UTF-8",
+				"Link":         `</content?envelope=true&isAnnotatedBy=http%3A%2F%2Fapi.ft.com%2Fthings%2F44129750-7616-11e8-b45a-da24cd01f044&limit=1&page=3>; rel="next", </content?envelope=true&isAnnotatedBy=http%3A%2F%2Fapi.ft.com%2Fthings%2F44129750-7616-11e8-b45a-da24cd01f044&limit=1&page=1>; rel="prev"`,
+			},
+		},
+		{
+			testName:           "Success for request with envelope through the Accept header",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			requestHeaders:     map[string]string{"Accept": "application/vnd.ft.content-list+json"},
+			expectedStatusCode: 200,
+			expectedBody:       `{"items":[{"id":"http://www.ft.com/things/e89db5e2-760d-11e8-b45a-da24cd01f044","apiUrl":"http://api.ft.com/content/e89db5e2-760d-11e8-b45a-da24cd01f044"}],"total":1,"page":1,"limit":50,"links":{}}` + "\n",
+			expectedHeaders:    map[string]string{"Content-Type": "application/vnd.ft.content-list+json; charset=UTF-8", "Link": ""},
+		},
+		{
+			testName:           "Success for request without envelope returns Link headers",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID, testContent2UUID},
+			contentLimit:       "1",
+			expectedStatusCode: 200,
+			expectedBody:       `[{"id":"http://www.ft.com/things/e89db5e2-760d-11e8-b45a-da24cd01f044","apiUrl":"http://api.ft.com/content/e89db5e2-760d-11e8-b45a-da24cd01f044"}]` + "\n",
+			expectedHeaders: map[string]string{
+				"Content-Type": "application/json; charset=UTF-8",
+				"Link":         `</content?isAnnotatedBy=http%3A%2F%2Fapi.ft.com%2Fthings%2F44129750-7616-11e8-b45a-da24cd01f044&limit=1&page=2>; rel="next"`,
+			},
+		},
+		{
+			testName:           "Success for request whose next page is beyond the maximum pagination depth links to it with a cursor",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID, testContent2UUID, testContentUUID},
+			page:               "500",
+			contentLimit:       "2",
+			expectedStatusCode: 200,
+			expectedHeaders: map[string]string{
+				"Link": `</content?cursor=` + content.Cursor{Sort: "publishedDate", UUID: testContent2UUID}.Encode() + `&isAnnotatedBy=http%3A%2F%2Fapi.ft.com%2Fthings%2F44129750-7616-11e8-b45a-da24cd01f044&limit=2>; rel="next", ` +
+					`</content?isAnnotatedBy=http%3A%2F%2Fapi.ft.com%2Fthings%2F44129750-7616-11e8-b45a-da24cd01f044&limit=2&page=499>; rel="prev"`,
+			},
+		},
+		{
+			testName:           "Bad Request: query param 'envelope' is invalid",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			extraParams:        "envelope=yes",
+			expectedStatusCode: 400,
+			expectedDetail:     `provided value for envelope, yes, could not be parsed.`,
+		},
+		{
+			testName:           "Success for request with fields",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			extraParams:        "fields=title,publishedDate&fields=types",
+			expectedStatusCode: 200,
+			expectedParams:     func(p *content.RequestParams) { p.Fields = []string{"title", "publishedDate", "types"} },
+		},
+		{
+			testName:           "Bad Request: query param 'fields' is not supported",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			extraParams:        "fields=title,bodyXML",
+			expectedStatusCode: 400,
+			expectedDetail:     `provided value for fields, bodyXML, is not a supported content field.`,
+		},
+		{
+			testName:           "Bad Request: query param 'fields' asks for standfirst, which isn't stored",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			extraParams:        "fields=standfirst",
+			expectedStatusCode: 400,
+			expectedDetail:     `provided value for fields, standfirst, is not a supported content field.`,
+		},
+		{
+			testName:           "Success for request including annotations",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			extraParams:        "include=annotation",
+			expectedStatusCode: 200,
+			expectedParams:     func(p *content.RequestParams) { p.IncludeAnnotations = true },
+		},
+		{
+			testName:           "Bad Request: query param 'include' is not supported",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			extraParams:        "include=provenance",
+			expectedStatusCode: 400,
+			expectedDetail:     `provided value for include, provenance, is not supported. Expecting annotation.`,
+		},
+		{
+			testName:           "Success for request with predicates",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			extraParams:        "predicate=about&predicate=majorMentions",
+			expectedStatusCode: 200,
+			expectedParams:     func(p *content.RequestParams) { p.Predicates = []string{"about", "majorMentions"} },
+		},
+		{
+			testName:           "Bad Request: query param 'predicate' is not supported",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			extraParams:        "predicate=about&predicate=isAbout",
+			expectedStatusCode: 400,
+			expectedDetail:     `provided value for predicate, isAbout, is not a supported annotation predicate.`,
+		},
+		{
+			testName:           "Success for request with content types",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			extraParams:        "type=Article&type=Video&excludeType=LiveBlogPackage",
+			expectedStatusCode: 200,
+			expectedParams: func(p *content.RequestParams) {
+				p.ContentTypes, p.ExcludedContentTypes = []string{"Article", "Video"}, []string{"LiveBlogPackage"}
+			},
+		},
+		{
+			testName:           "Bad Request: query param 'type' is not supported",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			extraParams:        "type=Podcast",
+			expectedStatusCode: 400,
+			expectedDetail:     `provided value for type, Podcast, is not a supported content type.`,
+		},
+		{
+			testName:           "Bad Request: query param 'excludeType' is not supported",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			extraParams:        "excludeType=article",
+			expectedStatusCode: 400,
+			expectedDetail:     `provided value for excludeType, article, is not a supported content type.`,
 		},
 		{
 			testName:           "Backend Error returns 503",
 			conceptID:          testConceptID,
 			contentList:        []string{testContentUUID},
 			expectedStatusCode: 503,
-			expectedBody:       `{"message": "Backend error returning content for concept with uuid 44129750-7616-11e8-b45a-da24cd01f044"}`,
+			expectedDetail:     `Backend error returning content for concept with uuid 44129750-7616-11e8-b45a-da24cd01f044`,
 			backendError:       errors.New("there was a problem"),
 		},
+		{
+			testName:           "Success for request with several concepts",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			extraParams:        "isAnnotatedBy=" + anotherConceptID + "&operator=and",
+			expectedStatusCode: 200,
+		},
+		{
+			testName:           "Success for request with any of several concepts",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID, testContent2UUID},
+			contentLimit:       "1",
+			extraParams:        "isAnnotatedBy=" + anotherConceptID + "&operator=or",
+			expectedStatusCode: 200,
+			expectedParams:     func(p *content.RequestParams) { p.ContentLimit, p.MatchAnyConcept = 1, true },
+			expectedHeaders:    map[string]string{"X-Next-Cursor": content.Cursor{Sort: "publishedDate", UUID: testContentUUID}.Encode()},
+		},
+		{
+			testName:           "Bad Request: one of several concepts is not a valid uuid",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			extraParams:        "isAnnotatedBy=not-a-uuid",
+			expectedStatusCode: 400,
+			expectedDetail:     `not-a-uuid extracted from request URL was not valid uuid`,
+		},
+		{
+			testName:           "Bad Request: query param 'operator' is not supported",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			extraParams:        "isAnnotatedBy=" + anotherConceptID + "&operator=xor",
+			expectedStatusCode: 400,
+			expectedDetail:     `provided value for operator, xor, is not supported. Expecting and or or.`,
+		},
+		{
+			testName:           "Success for request excluding concepts",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			extraParams:        "notAnnotatedBy=http://api.ft.com/things/" + anotherConceptID + "&notAnnotatedBy=" + testContent2UUID,
+			expectedStatusCode: 200,
+			expectedParams:     func(p *content.RequestParams) { p.ExcludedConceptUUIDs = []string{anotherConceptID, testContent2UUID} },
+		},
+		{
+			testName:           "Bad Request: query param 'notAnnotatedBy' is not a valid uuid",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			extraParams:        "notAnnotatedBy=opinion",
+			expectedStatusCode: 400,
+			expectedDetail:     `provided value for notAnnotatedBy, opinion, is not a valid concept URI or uuid.`,
+		},
+		{
+			testName:           "Success for request including narrower concepts",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			extraParams:        "includeNarrower=true&narrowerDepth=3",
+			expectedStatusCode: 200,
+			expectedParams:     func(p *content.RequestParams) { p.NarrowerDepth = 3 },
+		},
+		{
+			testName:           "Bad Request: query param 'includeNarrower' could not be parsed",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			extraParams:        "includeNarrower=maybe",
+			expectedStatusCode: 400,
+			expectedDetail:     `provided value for includeNarrower, maybe, could not be parsed.`,
+		},
+		{
+			testName:           "Bad Request: query param 'narrowerDepth' is above the maximum",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			extraParams:        "includeNarrower=true&narrowerDepth=4",
+			expectedStatusCode: 400,
+			expectedDetail:     `provided value for narrowerDepth should not be greater than: 3`,
+		},
+		{
+			testName:           "Bad Request: query param 'narrowerDepth' without includeNarrower",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			extraParams:        "narrowerDepth=2",
+			expectedStatusCode: 400,
+			expectedDetail:     `narrowerDepth can only be provided together with includeNarrower=true.`,
+		},
+		{
+			testName:           "Success for request including subsidiaries and memberships",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			extraParams:        "includeSubsidiaries=true&includeMemberships=true",
+			expectedStatusCode: 200,
+			expectedParams:     func(p *content.RequestParams) { p.IncludeSubsidiaries, p.IncludeMemberships = true, true },
+		},
+		{
+			testName:           "Bad Request: query param 'includeMemberships' could not be parsed",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			extraParams:        "includeMemberships=all",
+			expectedStatusCode: 400,
+			expectedDetail:     `provided value for includeMemberships, all, could not be parsed.`,
+		},
+		{
+			testName:           "Success for request with concorded concept",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			expectedStatusCode: 200,
+			expectedHeaders:    map[string]string{"X-Concordance-Used": "true"},
+		},
+		{
+			testName:           "Success for request with concept that isn't concorded",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			unconcorded:        true,
+			expectedStatusCode: 200,
+			expectedHeaders:    map[string]string{"X-Concordance-Used": "false"},
+		},
+		{
+			testName:           "Success for request with concept that isn't canonical",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			canonicalUUID:      anotherConceptID,
+			expectedStatusCode: 200,
+			expectedHeaders:    map[string]string{"X-Canonical-Concept": anotherConceptID},
+		},
+		{
+			testName:            "Redirect for request with concept that isn't canonical",
+			conceptID:           testConceptID,
+			contentList:         []string{testContentUUID},
+			contentLimit:        "10",
+			canonicalUUID:       anotherConceptID,
+			redirectToCanonical: true,
+			expectedStatusCode:  301,
+			expectedHeaders:     map[string]string{"Location": "/content?isAnnotatedBy=http%3A%2F%2Fapi.ft.com%2Fthings%2F" + anotherConceptID + "&limit=10"},
+		},
+		{
+			testName:            "Redirect for request with label and concept that isn't canonical drops the concept type",
+			conceptID:           "RawQuery",
+			contentList:         []string{testContentUUID},
+			extraParams:         "isAnnotatedBy=label:John%20Smith&isAnnotatedBy=" + testConceptID + "&conceptType=Person",
+			conceptMatches:      []string{anotherConceptID},
+			canonicalUUID:       anotherConceptID,
+			redirectToCanonical: true,
+			expectedStatusCode:  301,
+			expectedHeaders:     map[string]string{"Location": "/content?isAnnotatedBy=http%3A%2F%2Fapi.ft.com%2Fthings%2F" + anotherConceptID},
+		},
+		{
+			testName:            "Success for request with canonical concept in redirect mode",
+			conceptID:           testConceptID,
+			contentList:         []string{testContentUUID},
+			redirectToCanonical: true,
+			expectedStatusCode:  200,
+			expectedHeaders:     map[string]string{"X-Canonical-Concept": testConceptID},
+		},
+		{
+			testName:           "Success for request with authority identifier",
+			conceptID:          "RawQuery",
+			contentList:        []string{testContentUUID},
+			extraParams:        "authority=FACTSET&identifierValue=00BBBB-E",
+			conceptMatches:     []string{testConceptID},
+			expectedStatusCode: 200,
+			expectedHeaders:    map[string]string{"X-Canonical-Concept": testConceptID},
+		},
+		{
+			testName:           "No concept for authority identifier returns 404",
+			conceptID:          "RawQuery",
+			contentList:        []string{testContentUUID},
+			extraParams:        "authority=LEI&identifierValue=BQ4BKCS1HXDV9TTTTTTTT",
+			expectedStatusCode: 404,
+			expectedDetail:     `No concept found with LEI identifier BQ4BKCS1HXDV9TTTTTTTT`,
+		},
+		{
+			testName:           "Several concepts for authority identifier returns 409",
+			conceptID:          "RawQuery",
+			contentList:        []string{testContentUUID},
+			extraParams:        "authority=TME&identifierValue=Tk1F",
+			conceptMatches:     []string{testConceptID, anotherConceptID},
+			expectedStatusCode: 409,
+			expectedDetail:     `TME identifier Tk1F matches several concepts: 44129750-7616-11e8-b45a-da24cd01f044, 347e2eca-7860-11e8-b45a-da24cd01f044`,
+		},
+		{
+			testName:              "Bad Request: authority without identifierValue",
+			conceptID:             "RawQuery",
+			contentList:           []string{testContentUUID},
+			extraParams:           "authority=TME",
+			expectedStatusCode:    400,
+			expectedDetail:        `authority and identifierValue must be provided together.`,
+			expectedInvalidParams: []string{"authority", "identifierValue"},
+		},
+		{
+			testName:           "Bad Request: authority together with isAnnotatedBy",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			extraParams:        "authority=TME&identifierValue=Tk1F",
+			expectedStatusCode: 400,
+			expectedDetail:     `isAnnotatedBy and authority cannot be provided together.`,
+		},
+		{
+			testName:           "Unknown concept returns 404",
+			conceptID:          testConceptID,
+			unknownConcept:     true,
+			requestHeaders:     map[string]string{"X-Allow-Empty-Results": "true"},
+			expectedStatusCode: 404,
+			expectedDetail:     `Unknown concept with uuid 44129750-7616-11e8-b45a-da24cd01f044`,
+		},
+		{
+			testName:           "No content returns an empty list when empty results are allowed",
+			conceptID:          testConceptID,
+			requestHeaders:     map[string]string{"X-Allow-Empty-Results": "true"},
+			expectedStatusCode: 200,
+			expectedBody:       "[]\n",
+			expectedHeaders:    map[string]string{"X-Canonical-Concept": testConceptID},
+		},
+		{
+			testName:           "No content returns an empty envelope when empty results are allowed",
+			conceptID:          testConceptID,
+			requestHeaders:     map[string]string{"X-Allow-Empty-Results": "true", "Accept": "application/vnd.ft.content-list+json"},
+			expectedStatusCode: 200,
+			expectedBody:       `{"items":[],"total":0,"page":1,"limit":50,"links":{}}` + "\n",
+		},
+		{
+			testName:           "Bad Request: invalid X-Allow-Empty-Results header",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			requestHeaders:     map[string]string{"X-Allow-Empty-Results": "sometimes"},
+			expectedStatusCode: 400,
+			expectedDetail:     `provided value for X-Allow-Empty-Results header, sometimes, could not be parsed.`,
+		},
+		{
+			testName:           "Success for request with label",
+			conceptID:          "RawQuery",
+			contentList:        []string{testContentUUID},
+			extraParams:        "isAnnotatedBy=label:Smithy&conceptType=Person",
+			conceptMatches:     []string{testConceptID},
+			expectedStatusCode: 200,
+			expectedHeaders:    map[string]string{"X-Canonical-Concept": testConceptID},
+		},
+		{
+			testName:           "No concept for label returns 404",
+			conceptID:          "RawQuery",
+			contentList:        []string{testContentUUID},
+			extraParams:        "isAnnotatedBy=label:Smithy",
+			expectedStatusCode: 404,
+			expectedDetail:     `No concept found with label Smithy, aliases are only matched when conceptType is given`,
+		},
+		{
+			testName:           "Several concepts for label returns 300 with the candidates",
+			conceptID:          "RawQuery",
+			contentList:        []string{testContentUUID},
+			extraParams:        "isAnnotatedBy=label:Smithy",
+			conceptMatches:     []string{testConceptID, anotherConceptID},
+			expectedStatusCode: 300,
+			expectedBody: `{"message":"Label Smithy matches several concepts, use conceptType or one of the candidate ids instead.","candidates":[` +
+				`{"id":"http://api.ft.com/things/44129750-7616-11e8-b45a-da24cd01f044","apiUrl":"http://api.ft.com/people/44129750-7616-11e8-b45a-da24cd01f044","prefLabel":"Smithy","types":["http://www.ft.com/ontology/person/Person"]},` +
+				`{"id":"http://api.ft.com/things/347e2eca-7860-11e8-b45a-da24cd01f044","apiUrl":"http://api.ft.com/people/347e2eca-7860-11e8-b45a-da24cd01f044","prefLabel":"Smithy","types":["http://www.ft.com/ontology/person/Person"]}]}` + "\n",
+		},
+		{
+			testName:           "Bad Request: empty label",
+			conceptID:          "RawQuery",
+			contentList:        []string{testContentUUID},
+			extraParams:        "isAnnotatedBy=label:",
+			expectedStatusCode: 400,
+			expectedDetail:     `Missing label in isAnnotatedBy value label:`,
+		},
+		{
+			testName:           "Bad Request: unsupported conceptType",
+			conceptID:          "RawQuery",
+			contentList:        []string{testContentUUID},
+			extraParams:        "isAnnotatedBy=label:Smithy&conceptType=Content",
+			conceptMatches:     []string{testConceptID},
+			expectedStatusCode: 400,
+			expectedDetail:     `provided value for conceptType, Content, is not a supported concept type.`,
+		},
+		{
+			testName:           "Bad Request: conceptType without label",
+			conceptID:          testConceptID,
+			contentList:        []string{testContentUUID},
+			extraParams:        "conceptType=Person",
+			expectedStatusCode: 400,
+			expectedDetail:     `conceptType can only be provided together with a label: value for isAnnotatedBy.`,
+		},
+		{
+			testName:           "Bad Request: invalid limit is reported before the concept is looked up by identifier",
+			conceptID:          "RawQuery",
+			extraParams:        "authority=FACTSET&identifierValue=00BBBB-E&limit=null",
+			backendError:       errors.New("the concept shouldn't be looked up"),
+			expectedStatusCode: 400,
+			expectedDetail:     `provided value for limit, null, could not be parsed.`,
+		},
+		{
+			testName:           "Bad Request: invalid sort is reported before the concept is looked up by label",
+			conceptID:          "RawQuery",
+			extraParams:        "isAnnotatedBy=label:Smithy&sort=oldest",
+			backendError:       errors.New("the concept shouldn't be looked up"),
+			expectedStatusCode: 400,
+			expectedDetail:     `provided value for sort, oldest, is not a supported sort order.`,
+		},
+		{
+			testName:           "Success for request with organisation URI",
+			conceptID:          "RawQuery",
+			contentList:        []string{testContentUUID},
+			extraParams:        "isAnnotatedBy=https://api.ft.com/organisations/" + testConceptID,
+			expectedStatusCode: 200,
+			expectedHeaders:    map[string]string{"X-Canonical-Concept": testConceptID},
+		},
+		{
+			testName:           "Success for request with upper case www.ft.com URI",
+			conceptID:          "RawQuery",
+			contentList:        []string{testContentUUID},
+			extraParams:        "isAnnotatedBy=http://WWW.FT.COM/thing/" + strings.ToUpper(testConceptID),
+			expectedStatusCode: 200,
+			expectedHeaders:    map[string]string{"X-Canonical-Concept": testConceptID},
+		},
+		{
+			testName:           "Bad Request: concept URI has an unsupported host",
+			conceptID:          "RawQuery",
+			contentList:        []string{testContentUUID},
+			extraParams:        "isAnnotatedBy=http://example.com/things/" + testConceptID,
+			expectedStatusCode: 400,
+			expectedDetail:     `http://example.com/things/44129750-7616-11e8-b45a-da24cd01f044 is not a supported concept URI. Expecting a uuid, an http(s)://api.ft.com/things/, concepts/, organisations/, people/ or brands/ URI, or an http(s)://www.ft.com/thing/ URI`,
+		},
+		{
+			testName:              "Bad Request: concept uuid has a prefix",
+			conceptID:             "garbage-" + testConceptID,
+			contentList:           []string{testContentUUID},
+			expectedStatusCode:    400,
+			expectedDetail:        `garbage-44129750-7616-11e8-b45a-da24cd01f044 extracted from request URL was not valid uuid`,
+			expectedInvalidParams: []string{"isAnnotatedBy"},
+		},
+		{
+			testName:              "Bad Request: quotes in the concept uuid are encoded in the problem",
+			conceptID:             "RawQuery",
+			contentList:           []string{testContentUUID},
+			extraParams:           `isAnnotatedBy=%22}{"bad%22`,
+			expectedStatusCode:    400,
+			expectedDetail:        `"}{"bad" extracted from request URL was not valid uuid`,
+			expectedInvalidParams: []string{"isAnnotatedBy"},
+		},
+		{
+			testName:           "Backend error returns a problem",
+			conceptID:          testConceptID,
+			backendError:       errors.New("neo4j is down"),
+			requestHeaders:     map[string]string{"X-Request-Id": "tid_test"},
+			expectedStatusCode: 503,
+			expectedBody: `{"type":"https://github.com/Financial-Times/public-content-by-concept-api#backend-unavailable","title":"Backend unavailable","status":503,` +
+				`"detail":"Backend error returning content for concept with uuid 44129750-7616-11e8-b45a-da24cd01f044","transactionId":"tid_test"}` + "\n",
+		},
+		{
+			testName:           "No content for several concepts returns 404",
+			conceptID:          testConceptID,
+			extraParams:        "isAnnotatedBy=" + anotherConceptID,
+			expectedStatusCode: 404,
+			expectedDetail:     `No content found for concepts with uuids 44129750-7616-11e8-b45a-da24cd01f044, 347e2eca-7860-11e8-b45a-da24cd01f044`,
+		},
 		{
 			testName:           "No content for concept returns 404",
 			conceptID:          testConceptID,
 			expectedStatusCode: 404,
-			expectedBody:       `{"message": "No content found for concept with uuid 44129750-7616-11e8-b45a-da24cd01f044"}`,
+			expectedHeaders:    map[string]string{"Vary": "Accept, X-Allow-Empty-Results"},
+			expectedDetail:     `No content found for concept with uuid 44129750-7616-11e8-b45a-da24cd01f044`,
 		},
 	}
 
 	for _, test := range tests {
 		var reqURL string
-		ds := dummyService{test.contentList, test.backendError}
-		handler := Handler{ContentService: &ds, CacheControlHeader: "10", Log: log}
+		ds := dummyService{contentIDList: test.contentList, backendErr: test.backendError, unconcorded: test.unconcorded, canonicalUUID: test.canonicalUUID, conceptMatches: test.conceptMatches, unknownConcept: test.unknownConcept}
+		handler := Handler{ContentService: &ds, CacheControlHeader: "10", Log: log, Now: testNow, MaxLimit: 100, MaxPaginationDepth: 1000, MaxNarrowerDepth: 3, RedirectToCanonical: test.redirectToCanonical}
 
 		rec := httptest.NewRecorder()
 		if test.conceptID == "" {
 			reqURL = "/content"
 		} else if test.conceptID == "NullURI" {
 			reqURL = "/content?isAnnotatedBy="
+		} else if test.conceptID == "RawQuery" {
+			reqURL = "/content?" + test.extraParams
 		} else if test.conceptID == anotherConceptID {
 			reqURL = "/content?isAnnotatedBy=" + anotherConceptID
 		} else {
-			reqURL = buildURL(test.conceptID, test.fromDate, test.toDate, test.page, test.contentLimit)
+			reqURL = buildURL(test.conceptID, test.fromDate, test.toDate, test.page, test.contentLimit, test.extraParams)
+		}
+		req := newRequest("GET", reqURL)
+		for header, value := range test.requestHeaders {
+			req.Header.Set(header, value)
+		}
+		handler.GetContentByConcept(rec, req)
+		if test.expectedParams != nil {
+			expected := content.RequestParams{Page: defaultPage, ContentLimit: defaultLimit, Sort: content.DefaultSort}
+			test.expectedParams(&expected)
+			assert.Equal(expected, ds.params, "Wrong request params for %s", test.testName)
 		}
-		handler.GetContentByConcept(rec, newRequest("GET", reqURL))
 		assert.Equal(test.expectedStatusCode, rec.Code, "There was an error returning the correct status code")
 		if test.expectedBody != "" {
 			assert.Equal(test.expectedBody, rec.Body.String(), "Wrong body")
 		}
+		if rec.Code >= 400 {
+			var p problem
+			assert.Equal("application/problem+json", rec.Header().Get("Content-Type"), "Wrong content type for %s", test.testName)
+			assert.NoError(json.Unmarshal(rec.Body.Bytes(), &p), "Invalid problem for %s", test.testName)
+			assert.Equal(rec.Code, p.Status, "Wrong problem status for %s", test.testName)
+			assert.NotEmpty(p.Type, "Missing problem type for %s", test.testName)
+			assert.Equal(rec.Header().Get("X-Request-Id"), p.TransactionID, "Wrong transaction id for %s", test.testName)
+			if test.expectedDetail != "" {
+				assert.Equal(test.expectedDetail, p.Detail, "Wrong problem detail for %s", test.testName)
+			}
+			if test.expectedInvalidParams != nil {
+				var params []string
+				for _, param := range p.InvalidParams {
+					params = append(params, param.Name)
+				}
+				assert.Equal(test.expectedInvalidParams, params, "Wrong invalid params for %s", test.testName)
+			}
+		}
+		for header, value := range test.expectedHeaders {
+			assert.Equal(value, rec.Header().Get(header), "Wrong value for header %s", header)
+		}
 	}
 }
 
-func buildURL(conceptID, fromDate, toDate, page, contentLimit string) string {
+func testNow() time.Time {
+	return time.Date(2018, time.June, 20, 12, 0, 0, 0, time.UTC)
+}
+
+func buildURL(conceptID, fromDate, toDate, page, contentLimit, extraParams string) string {
 	var URL = fmt.Sprintf("/content?isAnnotatedBy=http://api.ft.com/things/%s", conceptID)
 	if fromDate != "" {
 		URL = URL + fmt.Sprintf("&fromDate=%s", fromDate)
@@ -189,6 +963,9 @@ func buildURL(conceptID, fromDate, toDate, page, contentLimit string) string {
 	if page != "" {
 		URL = URL + fmt.Sprintf("&page=%s", page)
 	}
+	if extraParams != "" {
+		URL = URL + "&" + extraParams
+	}
 	return URL
 }
 
@@ -201,27 +978,91 @@ func newRequest(method, url string) *http.Request {
 }
 
 type dummyService struct {
-	contentIDList []string
-	backendErr    error
+	contentIDList  []string
+	backendErr     error
+	unconcorded    bool
+	canonicalUUID  string
+	conceptMatches []string
+	unknownConcept bool
+	// params are the request params of the last content request
+	params content.RequestParams
 }
 
-func (dS dummyService) GetContentForConcept(conceptUUID string, params content.RequestParams) ([]content.Content, error) {
+func (dS *dummyService) GetContentForConcepts(conceptUUIDs []string, params content.RequestParams) (content.ContentPage, error) {
+	dS.params = params
 	if dS.backendErr != nil {
-		return nil, dS.backendErr
+		return content.ContentPage{}, dS.backendErr
+	}
+	if dS.unknownConcept {
+		return content.ContentPage{}, content.ErrConceptNotFound
 	}
 	if len(dS.contentIDList) == 0 && dS.backendErr == nil {
-		return nil, content.ErrContentNotFound
+		resolved, _ := dS.ResolveConcepts(conceptUUIDs)
+		return content.ContentPage{Concepts: resolved}, content.ErrContentNotFound
+	}
+
+	contentIDList := dS.contentIDList
+	var nextCursor *content.Cursor
+	if len(contentIDList) > params.ContentLimit {
+		contentIDList = contentIDList[:params.ContentLimit]
+		nextCursor = &content.Cursor{Sort: params.Sort, UUID: contentIDList[len(contentIDList)-1]}
 	}
 
 	cntList := make([]content.Content, 0)
-	for _, contentID := range dS.contentIDList {
+	for _, contentID := range contentIDList {
 		var con = content.Content{}
 		con.APIURL = mapper.APIURL(contentID, []string{"Content", "Thing"}, "")
 		con.ID = content.ThingsPrefix + contentID
 		cntList = append(cntList, con)
 	}
 
-	return cntList, nil
+	contentPage := content.ContentPage{Content: cntList, NextCursor: nextCursor}
+	contentPage.Concepts, _ = dS.ResolveConcepts(conceptUUIDs)
+	if params.IncludeTotal {
+		contentPage.Total = len(dS.contentIDList)
+	}
+	return contentPage, nil
+}
+
+func (dS dummyService) FindConceptsByIdentifier(authority, identifierValue string) ([]string, error) {
+	if dS.backendErr != nil {
+		return nil, dS.backendErr
+	}
+	return dS.conceptMatches, nil
+}
+
+func (dS dummyService) FindConceptsByLabel(label, conceptType string) ([]content.ConceptCandidate, error) {
+	if dS.backendErr != nil {
+		return nil, dS.backendErr
+	}
+	var candidates []content.ConceptCandidate
+	for _, conceptUUID := range dS.conceptMatches {
+		candidates = append(candidates, content.ConceptCandidate{
+			ID:        mapper.IDURL(conceptUUID),
+			APIURL:    mapper.APIURL(conceptUUID, []string{"Person"}, ""),
+			PrefLabel: label,
+			Types:     mapper.TypeURIs([]string{"Person"}),
+		})
+	}
+	return candidates, nil
+}
+
+func (dS dummyService) ResolveConcepts(conceptUUIDs []string) ([]content.ResolvedConcept, error) {
+	if dS.backendErr != nil {
+		return nil, dS.backendErr
+	}
+	var resolved []content.ResolvedConcept
+	for _, conceptUUID := range conceptUUIDs {
+		concept := content.ResolvedConcept{UUID: conceptUUID, CanonicalUUID: conceptUUID}
+		if dS.canonicalUUID != "" {
+			concept.CanonicalUUID = dS.canonicalUUID
+		}
+		if dS.unconcorded {
+			concept.CanonicalUUID = ""
+		}
+		resolved = append(resolved, concept)
+	}
+	return resolved, nil
 }
 
 func (dS dummyService) CheckConnection() (string, error) {
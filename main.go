@@ -55,6 +55,36 @@ func main() {
 		EnvVar: "RECORD_HTTP_METRICS",
 		Value:  false,
 	})
+	maxLimit := app.Int(cli.IntOpt{
+		Name:   "max-limit",
+		Value:  500,
+		Desc:   "Maximum number of content items that can be requested in a single page",
+		EnvVar: "MAX_LIMIT",
+	})
+	maxPaginationDepth := app.Int(cli.IntOpt{
+		Name:   "max-pagination-depth",
+		Value:  10000,
+		Desc:   "Maximum number of content items that can be reached with the page parameter, deeper results need the cursor parameter",
+		EnvVar: "MAX_PAGINATION_DEPTH",
+	})
+	maxNarrowerDepth := app.Int(cli.IntOpt{
+		Name:   "max-narrower-depth",
+		Value:  5,
+		Desc:   "Maximum number of levels of narrower concepts that can be included with the includeNarrower parameter",
+		EnvVar: "MAX_NARROWER_DEPTH",
+	})
+	redirectToCanonical := app.Bool(cli.BoolOpt{
+		Name:   "redirect-to-canonical",
+		Desc:   "Redirect requests for concepts that aren't canonical to the URL of the canonical concepts",
+		EnvVar: "REDIRECT_TO_CANONICAL",
+		Value:  false,
+	})
+	relevanceHalfLife := app.String(cli.StringOpt{
+		Name:   "relevance-half-life",
+		Value:  "168h",
+		Desc:   "Age at which the relevance of content is halved when sorting by recency-relevance. e.g. 72h",
+		EnvVar: "RELEVANCE_HALF_LIFE",
+	})
 	logLevel := app.String(cli.StringOpt{
 		Name:   "logLevel",
 		Value:  "INFO",
@@ -77,15 +107,25 @@ func main() {
 			log.WithError(err).Fatal("Failed to parse cache duration value")
 		}
 
+		halfLife, err := time.ParseDuration(*relevanceHalfLife)
+		if err != nil || halfLife <= 0 {
+			log.WithError(err).Fatal("Failed to parse relevance half-life value")
+		}
+
 		config := ServerConfig{
-			Port:           *port,
-			APIYMLPath:     *apiYml,
-			CacheTime:      duration,
-			RecordMetrics:  *recordMetrics,
-			AppSystemCode:  *appSystemCode,
-			AppName:        *appName,
-			AppDescription: appDescription,
-			NeoURL:         *neoURL,
+			Port:                *port,
+			APIYMLPath:          *apiYml,
+			CacheTime:           duration,
+			RecordMetrics:       *recordMetrics,
+			MaxLimit:            *maxLimit,
+			MaxPaginationDepth:  *maxPaginationDepth,
+			MaxNarrowerDepth:    *maxNarrowerDepth,
+			RedirectToCanonical: *redirectToCanonical,
+			RelevanceHalfLife:   halfLife,
+			AppSystemCode:       *appSystemCode,
+			AppName:             *appName,
+			AppDescription:      appDescription,
+			NeoURL:              *neoURL,
 			NeoConfig: neoutils.ConnectionConfig{
 				BatchSize:     1024,
 				Transactional: false,
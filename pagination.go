@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Financial-Times/public-content-by-concept-api/v2/content"
+)
+
+const contentListMediaType = "application/vnd.ft.content-list+json"
+
+// contentListEnvelope wraps a page of content with the information clients need to paginate
+type contentListEnvelope struct {
+	Items []content.Content `json:"items"`
+	Total int               `json:"total"`
+	Page  int               `json:"page,omitempty"`
+	Limit int               `json:"limit"`
+	Links paginationLinks   `json:"links"`
+}
+
+type paginationLinks struct {
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
+
+// envelopeRequested reports whether the client opted in to the content list envelope,
+// either through the Accept header or the envelope query parameter
+func envelopeRequested(r *http.Request, val url.Values) (bool, error) {
+	envelopeParam := val.Get("envelope")
+	if envelopeParam != "" {
+		envelope, err := strconv.ParseBool(envelopeParam)
+		if err != nil {
+			return false, newParamError(fmt.Sprintf("provided value for envelope, %s, could not be parsed.", envelopeParam), "envelope")
+		}
+		return envelope, nil
+	}
+	return strings.Contains(r.Header.Get("Accept"), contentListMediaType), nil
+}
+
+// buildPaginationLinks returns the links to the pages around the current one.
+// Requests paginated with a cursor only get a link to the next page. When the next page lies
+// beyond maxPaginationDepth, it can only be reached with a cursor so the next link uses one.
+func buildPaginationLinks(r *http.Request, params content.RequestParams, contentPage content.ContentPage, maxPaginationDepth int) paginationLinks {
+	var links paginationLinks
+	nextWithCursor := func(q url.Values) {
+		q.Del("page")
+		q.Set("cursor", contentPage.NextCursor.Encode())
+	}
+	if params.Cursor != nil {
+		if contentPage.NextCursor != nil {
+			links.Next = pageURL(r, nextWithCursor)
+		}
+		return links
+	}
+
+	if contentPage.NextCursor != nil {
+		if maxPaginationDepth > 0 && (params.Page+1)*params.ContentLimit > maxPaginationDepth {
+			links.Next = pageURL(r, nextWithCursor)
+		} else {
+			links.Next = pageURL(r, func(q url.Values) {
+				q.Set("page", strconv.Itoa(params.Page+1))
+			})
+		}
+	}
+	if params.Page > defaultPage {
+		links.Prev = pageURL(r, func(q url.Values) {
+			q.Set("page", strconv.Itoa(params.Page-1))
+		})
+	}
+	return links
+}
+
+func pageURL(r *http.Request, update func(q url.Values)) string {
+	q := r.URL.Query()
+	update(q)
+	return r.URL.Path + "?" + q.Encode()
+}
+
+// linkHeader formats the pagination links as described in RFC 8288
+func linkHeader(links paginationLinks) string {
+	var values []string
+	if links.Next != "" {
+		values = append(values, fmt.Sprintf(`<%s>; rel="next"`, links.Next))
+	}
+	if links.Prev != "" {
+		values = append(values, fmt.Sprintf(`<%s>; rel="prev"`, links.Prev))
+	}
+	return strings.Join(values, ", ")
+}
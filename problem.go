@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	transactionidutils "github.com/Financial-Times/transactionid-utils-go"
+)
+
+const problemMediaType = "application/problem+json"
+
+// problemTypeBase is where the problem types are documented, each of them is a section of the README
+const problemTypeBase = "https://github.com/Financial-Times/public-content-by-concept-api#"
+
+// Problem types identify the kind of error independently of the detail message, which may change
+const (
+	problemInvalidParameter   = problemTypeBase + "invalid-parameter"
+	problemConceptNotFound    = problemTypeBase + "concept-not-found"
+	problemContentNotFound    = problemTypeBase + "content-not-found"
+	problemAmbiguousConcept   = problemTypeBase + "ambiguous-concept"
+	problemBackendUnavailable = problemTypeBase + "backend-unavailable"
+	problemInternalError      = problemTypeBase + "internal-error"
+)
+
+var problemTitles = map[string]string{
+	problemInvalidParameter:   "Invalid request parameter",
+	problemConceptNotFound:    "Concept not found",
+	problemContentNotFound:    "Content not found",
+	problemAmbiguousConcept:   "Ambiguous concept",
+	problemBackendUnavailable: "Backend unavailable",
+	problemInternalError:      "Internal error",
+}
+
+// problem is an RFC 7807 error response
+type problem struct {
+	Type          string         `json:"type"`
+	Title         string         `json:"title"`
+	Status        int            `json:"status"`
+	Detail        string         `json:"detail"`
+	InvalidParams []invalidParam `json:"invalid-params,omitempty"`
+	TransactionID string         `json:"transactionId,omitempty"`
+}
+
+// invalidParam names a parameter at fault, the detail of the problem explains what is wrong with it
+type invalidParam struct {
+	Name string `json:"name"`
+}
+
+// paramError is returned when request parameters are invalid, it names the parameters at fault
+type paramError struct {
+	msg    string
+	params []string
+}
+
+func (e *paramError) Error() string {
+	return e.msg
+}
+
+func newParamError(msg string, params ...string) error {
+	return &paramError{msg: msg, params: params}
+}
+
+// writeProblem writes an RFC 7807 problem, the transaction id is taken from the response headers
+func writeProblem(w http.ResponseWriter, status int, problemType, detail string, params ...string) {
+	p := problem{
+		Type:          problemType,
+		Title:         problemTitles[problemType],
+		Status:        status,
+		Detail:        detail,
+		TransactionID: w.Header().Get(transactionidutils.TransactionIDHeader),
+	}
+	for _, param := range params {
+		p.InvalidParams = append(p.InvalidParams, invalidParam{Name: param})
+	}
+
+	w.Header().Set("Content-Type", problemMediaType)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(p)
+}
+
+// writeBadRequest writes the problem for an invalid request, listing the parameters named by err
+func writeBadRequest(w http.ResponseWriter, err error) {
+	var pe *paramError
+	if errors.As(err, &pe) {
+		writeProblem(w, http.StatusBadRequest, problemInvalidParameter, pe.msg, pe.params...)
+		return
+	}
+	writeProblem(w, http.StatusBadRequest, problemInvalidParameter, err.Error())
+}
@@ -24,6 +24,12 @@ type ServerConfig struct {
 	CacheTime     time.Duration
 	RecordMetrics bool
 
+	MaxLimit            int
+	MaxPaginationDepth  int
+	MaxNarrowerDepth    int
+	RedirectToCanonical bool
+	RelevanceHalfLife   time.Duration
+
 	AppSystemCode  string
 	AppName        string
 	AppDescription string
@@ -44,9 +50,14 @@ func StartServer(config ServerConfig, log *logger.UPPLogger) (func(), error) {
 	}
 
 	handler := Handler{
-		ContentService:     cbcService,
-		CacheControlHeader: strconv.FormatFloat(config.CacheTime.Seconds(), 'f', 0, 64),
-		Log:                log,
+		ContentService:      cbcService,
+		CacheControlHeader:  strconv.FormatFloat(config.CacheTime.Seconds(), 'f', 0, 64),
+		Log:                 log,
+		MaxLimit:            config.MaxLimit,
+		MaxPaginationDepth:  config.MaxPaginationDepth,
+		MaxNarrowerDepth:    config.MaxNarrowerDepth,
+		RedirectToCanonical: config.RedirectToCanonical,
+		RelevanceHalfLife:   config.RelevanceHalfLife,
 	}
 
 	hs := &HealthcheckService{